@@ -6,12 +6,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+	_ "time/tzdata" // car time zones must resolve even without system zone data
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
 	"rentora-go/internal/handler"
+	"rentora-go/internal/middleware"
 	"rentora-go/internal/model"
 	"rentora-go/internal/repository"
 	"rentora-go/internal/service"
@@ -40,30 +45,105 @@ func main() {
 
 	jwtSecret := []byte(jwtSecretStr)
 
+	// How long a booking may stay Pending before it expires, and how often to check
+	pendingExpiry := durationFromEnv("BOOKING_PENDING_EXPIRY", 48*time.Hour)
+	expiryInterval := durationFromEnv("BOOKING_EXPIRY_INTERVAL", 5*time.Minute)
+
+	// How long freed dates stay held for the next renter on the waitlist
+	waitlistHold := durationFromEnv("WAITLIST_HOLD", 24*time.Hour)
+
+	// How long Idempotency-Key responses are kept for replay
+	idempotencyTTL := durationFromEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+
+	// Tax rate included in prices, shown on invoices
+	taxRate := percentFromEnv("TAX_RATE_PERCENT", 0)
+
 	// Initialize database
-	dsn := dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName + "?charset=utf8mb4&parseTime=True&loc=Local"
+	dsn := dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName + "?charset=utf8mb4&parseTime=True&loc=UTC"
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Failed to connect to the database: %v", err)
 	}
 
 	// Auto-migrate
-	db.AutoMigrate(&model.User{}, 
+	models := []interface{}{&model.User{},
 		&model.Car{},
-	&model.Booking{})
+		&model.Booking{},
+		&model.BookingLineItem{},
+		&model.BookingAdjustment{},
+		&model.BookingEvent{},
+		&model.CarBlock{},
+		&model.CarExtra{},
+		&model.BookingInspection{},
+		&model.DepositLedgerEntry{},
+		&model.BookingInstallment{},
+		&model.WaitlistEntry{},
+		&model.BookingMessage{},
+		&model.MessageReadMarker{},
+		&model.IdempotencyRecord{}}
+	db.AutoMigrate(append(models, &model.SchemaMigration{})...)
+
+	// Times used to be stored in the server's local time; convert them to
+	// UTC once. Set DB_LEGACY_TIME_ZONE to the zone the old server ran in
+	// if it differs from this one, or to UTC if the data is already UTC.
+	legacyZone := time.Local
+	if name := os.Getenv("DB_LEGACY_TIME_ZONE"); name != "" {
+		if legacyZone, err = time.LoadLocation(name); err != nil {
+			log.Fatalf("Invalid DB_LEGACY_TIME_ZONE %q: %v", name, err)
+		}
+	}
+	converted, err := repository.ConvertLegacyTimesToUTC(db, legacyZone, models...)
+	if err != nil {
+		log.Fatalf("Failed to convert stored times to UTC: %v", err)
+	}
+	if converted {
+		log.Printf("Converted stored times from %s to UTC", legacyZone)
+	}
 
 	// Initialize dependencies
 	userRepo := repository.NewUserRepository(db)
 	carRepo := repository.NewCarRepository(db)
 	bookingRepo := repository.NewBookingRepository(db)
+	carBlockRepo := repository.NewCarBlockRepository(db)
+	carExtraRepo := repository.NewCarExtraRepository(db)
+	inspectionRepo := repository.NewInspectionRepository(db)
+	depositRepo := repository.NewDepositRepository(db)
+	installmentRepo := repository.NewInstallmentRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	messageRepo := repository.NewMessageRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
 
 	authService := service.NewAuthService(userRepo, []byte(jwtSecret))
 	carService := service.NewCarService(carRepo)
-	bookingService := service.NewBookingService(bookingRepo)
+	depositService := service.NewDepositService(depositRepo, bookingRepo, carRepo)
+	waitlistService := service.NewWaitlistService(waitlistRepo, bookingRepo, carRepo, service.LogNotifier{}, waitlistHold)
+	bookingService := service.NewBookingService(bookingRepo, carRepo, userRepo, depositService, waitlistService)
+	calendarService := service.NewCalendarService(bookingRepo, carRepo, carBlockRepo, waitlistRepo, waitlistService)
+	carBlockService := service.NewCarBlockService(carBlockRepo, bookingRepo, carRepo, waitlistService)
+	carExtraService := service.NewCarExtraService(carExtraRepo, carRepo)
+	inspectionService := service.NewInspectionService(inspectionRepo, bookingRepo, carRepo)
+	installmentService := service.NewInstallmentService(installmentRepo, bookingRepo, carRepo)
+	messageService := service.NewMessageService(messageRepo, bookingRepo, carRepo)
+	documentService := service.NewDocumentService(bookingRepo, carRepo, userRepo, inspectionRepo, taxRate)
+	expiryWorker := service.NewBookingExpiryWorker(bookingService, pendingExpiry, expiryInterval)
+	holdWorker := service.NewWaitlistHoldWorker(waitlistService, expiryInterval)
+	overdueWorker := service.NewInstallmentOverdueWorker(installmentService, expiryInterval)
+	purgeWorker := service.NewIdempotencyPurgeWorker(idempotencyRepo, idempotencyTTL, expiryInterval)
 
 	authHandler := handler.NewAuthHandler(authService)
 	carHandler := handler.NewCarHandler(carService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	carBlockHandler := handler.NewCarBlockHandler(carBlockService)
+	carExtraHandler := handler.NewCarExtraHandler(carExtraService)
+	inspectionHandler := handler.NewInspectionHandler(inspectionService)
+	depositHandler := handler.NewDepositHandler(depositService)
+	installmentHandler := handler.NewInstallmentHandler(installmentService)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	messageHandler := handler.NewMessageHandler(messageService)
+	documentHandler := handler.NewDocumentHandler(documentService)
+
+	idempotent := middleware.Idempotency(idempotencyRepo, idempotencyTTL)
 
 	// Set up routes
 	r := chi.NewRouter()
@@ -71,7 +151,7 @@ func main() {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", middleware.IdempotencyKeyHeader},
 		AllowCredentials: true,
 	}))
 
@@ -80,7 +160,16 @@ func main() {
 
     handler.RegisterUserRoutes(r, authHandler)
     handler.RegisterCarRoutes(r, carHandler)
-	handler.RegisterBookingRoutes(r, bookingHandler)
+	handler.RegisterBookingRoutes(r, bookingHandler, idempotent)
+	handler.RegisterCalendarRoutes(r, calendarHandler)
+	handler.RegisterCarBlockRoutes(r, carBlockHandler)
+	handler.RegisterCarExtraRoutes(r, carExtraHandler)
+	handler.RegisterInspectionRoutes(r, inspectionHandler)
+	handler.RegisterDepositRoutes(r, depositHandler, idempotent)
+	handler.RegisterInstallmentRoutes(r, installmentHandler, idempotent)
+	handler.RegisterWaitlistRoutes(r, waitlistHandler)
+	handler.RegisterMessageRoutes(r, messageHandler)
+	handler.RegisterDocumentRoutes(r, documentHandler)
 
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -105,6 +194,27 @@ func main() {
 		Handler: r,
 	}
 
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(4)
+	go func() {
+		defer workers.Done()
+		expiryWorker.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		holdWorker.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		overdueWorker.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		purgeWorker.Run(workerCtx)
+	}()
+
 	go func() {
 		log.Println("Server is running on port 8080")
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -121,5 +231,35 @@ func main() {
 	if err := srv.Shutdown(context.Background()); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
+	stopWorkers()
+	workers.Wait()
 	log.Println("Server stopped")
 }
+
+// durationFromEnv reads a duration such as "48h" from the environment,
+// falling back to def when the variable is unset.
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid duration for %s: %q", key, value)
+	}
+	return d
+}
+
+// percentFromEnv reads a percentage such as "7.5" from the environment,
+// falling back to def when the variable is unset.
+func percentFromEnv(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil || p < 0 || p > 100 {
+		log.Fatalf("Invalid percentage for %s: %q", key, value)
+	}
+	return p
+}
@@ -2,12 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"os"
 	"rentora-go/internal/middleware"
 	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
 	"rentora-go/internal/service"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -20,32 +24,79 @@ func NewBookingHandler(service *service.BookingService) *BookingHandler {
 	return &BookingHandler{service: service}
 }
 
+// CreateBookingRequest is the payload for creating a booking. Amounts are
+// calculated by the server, so the request does not carry any. Dates without
+// a UTC offset are read in the car's time zone.
+type CreateBookingRequest struct {
+	CarID         uint            `json:"car_id"`
+	StartDate     model.LocalTime `json:"start_date"`
+	EndDate       model.LocalTime `json:"end_date"`
+	PaymentMethod string          `json:"payment_method"`
+	// Extras from the car's catalog to add to the booking
+	Extras []service.ExtraChoice `json:"extras"`
+}
+
+// BookingResponse is a new booking together with its itemized price and how
+// it was confirmed: "instant" when accepted through Instant Book, "request"
+// when it waits for the owner.
+type BookingResponse struct {
+	*model.Booking
+	Price             *service.PriceBreakdown `json:"price"`
+	Confirmation      string                  `json:"confirmation"`
+	InstantBookIssues []string                `json:"instant_book_issues,omitempty"`
+}
+
 func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
-	var booking model.Booking
-	if err := json.NewDecoder(r.Body).Decode(&booking); err != nil {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	// Here you might add logic to check car availability, user validation, etc.
-	if err := h.service.CreateBooking(&booking); err != nil {
-		http.Error(w, "Error creating booking", http.StatusInternalServerError)
+	zone, err := h.service.CarZone(req.CarID)
+	if err != nil {
+		writeBookingError(w, err, "Error creating booking")
+		return
+	}
+
+	// The renter is always the authenticated user.
+	booking := model.Booking{
+		UserID:        userID,
+		CarID:         req.CarID,
+		StartDate:     req.StartDate.Resolve(zone),
+		EndDate:       req.EndDate.Resolve(zone),
+		PaymentMethod: req.PaymentMethod,
+	}
+
+	result, err := h.service.CreateBooking(&booking, req.Extras)
+	if err != nil {
+		writeBookingError(w, err, "Error creating booking")
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(booking)
+	json.NewEncoder(w).Encode(BookingResponse{
+		Booking:           &booking,
+		Price:             result.Price,
+		Confirmation:      result.Confirmation,
+		InstantBookIssues: result.InstantBookIssues,
+	})
 }
 
 func (h *BookingHandler) GetBookingsByUserID(w http.ResponseWriter, r *http.Request) {
-	userIDStr := chi.URLParam(r, "userID")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32) // Parse string to uint64
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	bookings, err := h.service.GetBookingsByUserID(uint(userID)) // Convert to uint
+	bookings, err := h.service.GetBookingsByUserID(userID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -55,6 +106,106 @@ func (h *BookingHandler) GetBookingsByUserID(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(bookings)
 }
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// GetOwnerBookings lists booking requests on the authenticated user's cars.
+// It accepts the optional query parameters status, car_id, from and to
+// (YYYY-MM-DD or RFC 3339), page and page_size.
+func (h *BookingHandler) GetOwnerBookings(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	var filter repository.BookingFilter
+
+	if status := query.Get("status"); status != "" {
+		filter.Status = model.BookingStatus(status)
+		if !filter.Status.IsValid() {
+			http.Error(w, "Invalid status", http.StatusBadRequest)
+			return
+		}
+	}
+	if carIDStr := query.Get("car_id"); carIDStr != "" {
+		carID, err := strconv.ParseUint(carIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid car ID", http.StatusBadRequest)
+			return
+		}
+		filter.CarID = uint(carID)
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(query.Get("from")); err != nil {
+		http.Error(w, "Invalid from date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseDateParam(query.Get("to")); err != nil {
+		http.Error(w, "Invalid to date", http.StatusBadRequest)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	bookings, total, err := h.service.GetOwnerBookings(ownerID, filter)
+	if err != nil {
+		http.Error(w, "Error retrieving bookings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"bookings":  bookings,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// parseDateParam parses a YYYY-MM-DD or RFC 3339 query value. An empty value
+// yields the zero time.
+func parseDateParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parsePagination reads the page and page_size query parameters, applying
+// defaults and capping the page size.
+func parsePagination(r *http.Request) (page, pageSize int, err error) {
+	page, pageSize = 1, defaultPageSize
+	query := r.URL.Query()
+	if value := query.Get("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			return 0, 0, errors.New("invalid page")
+		}
+	}
+	if value := query.Get("page_size"); value != "" {
+		if pageSize, err = strconv.Atoi(value); err != nil || pageSize < 1 {
+			return 0, 0, errors.New("invalid page size")
+		}
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize, nil
+}
+
 func (h *BookingHandler) GetBookingByID(w http.ResponseWriter, r *http.Request) {
 	bookingIDStr := chi.URLParam(r, "bookingID")
 	bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32) // Parse string to uint64
@@ -63,10 +214,16 @@ func (h *BookingHandler) GetBookingByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	// Cast to uint before passing to service
-	booking, err := h.service.GetBookingByID(uint(bookingID))
+	booking, err := h.service.GetBookingByID(uint(bookingID), userID)
 	if err != nil {
-		http.Error(w, "Booking not found", http.StatusNotFound)
+		writeBookingError(w, err, "Error retrieving booking")
 		return
 	}
 
@@ -75,14 +232,20 @@ func (h *BookingHandler) GetBookingByID(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	var booking model.Booking
 	if err := json.NewDecoder(r.Body).Decode(&booking); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.service.UpdateBooking(&booking); err != nil {
-		http.Error(w, "Error updating booking", http.StatusInternalServerError)
+	if err := h.service.UpdateBooking(&booking, userID); err != nil {
+		writeBookingError(w, err, "Error updating booking")
 		return
 	}
 
@@ -98,8 +261,14 @@ func (h *BookingHandler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteBooking(uint(bookingID)); err != nil {
-		http.Error(w, "Error deleting booking", http.StatusInternalServerError)
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.DeleteBooking(uint(bookingID), userID); err != nil {
+		writeBookingError(w, err, "Error deleting booking")
 		return
 	}
 
@@ -108,26 +277,98 @@ func (h *BookingHandler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
 
 
 func (h *BookingHandler) AcceptBooking(w http.ResponseWriter, r *http.Request) {
-	bookingIDStr := chi.URLParam(r, "bookingID")
-	bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32) // Parse string to uint64
+	h.changeStatus(w, r, h.service.AcceptBooking, "Booking accepted")
+}
+
+func (h *BookingHandler) DeclineBooking(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.DeclineBooking, "Booking declined")
+}
+
+func (h *BookingHandler) PickUpBooking(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.PickUpBooking, "Booking picked up")
+}
+
+// ReturnBooking completes the booking and reports any late return or
+// mileage charges added on top of its total.
+func (h *BookingHandler) ReturnBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, reason, ok := decodeStatusChange(w, r)
+	if !ok {
+		return
+	}
+
+	booking, err := h.service.ReturnBooking(bookingID, userID, reason)
 	if err != nil {
-		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		writeBookingError(w, err, "Error returning booking")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":          "Booking returned",
+		"adjustments":      booking.Adjustments,
+		"adjustment_total": booking.AdjustmentTotal,
+	})
+}
+
+func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, reason, ok := decodeStatusChange(w, r)
+	if !ok {
 		return
 	}
 
-	err = h.service.AcceptBooking(uint(bookingID)) // Convert to uint
+	booking, err := h.service.CancelBooking(bookingID, userID, reason)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBookingError(w, err, "Error cancelling booking")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       "Booking cancelled",
+		"refund_amount": booking.RefundAmount,
+	})
+}
+
+// StatusChangeRequest is the optional body of the lifecycle endpoints.
+type StatusChangeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// changeStatus runs one of the service's lifecycle operations for the booking
+// in the URL on behalf of the authenticated user and reports the outcome.
+func (h *BookingHandler) changeStatus(w http.ResponseWriter, r *http.Request, op func(bookingID, actorID uint, reason string) error, message string) {
+	bookingID, userID, reason, ok := decodeStatusChange(w, r)
+	if !ok {
+		return
+	}
+
+	if err := op(bookingID, userID, reason); err != nil {
+		writeBookingError(w, err, "Error updating booking")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Booking accepted"})
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
 }
 
+// decodeStatusChange reads the booking, the acting user and the optional
+// reason of a lifecycle request, writing an error response when invalid.
+func decodeStatusChange(w http.ResponseWriter, r *http.Request) (bookingID, userID uint, reason string, ok bool) {
+	bookingID, userID, ok = bookingRequestIDs(w, r)
+	if !ok {
+		return 0, 0, "", false
+	}
 
+	// The body is optional; an empty one simply carries no reason.
+	var req StatusChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return 0, 0, "", false
+	}
+	return bookingID, userID, req.Reason, true
+}
 
-func (h *BookingHandler) DeclineBooking(w http.ResponseWriter, r *http.Request) {
+func (h *BookingHandler) GetBookingHistory(w http.ResponseWriter, r *http.Request) {
 	bookingIDStr := chi.URLParam(r, "bookingID")
 	bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32) // Parse string to uint64
 	if err != nil {
@@ -135,30 +376,89 @@ func (h *BookingHandler) DeclineBooking(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.service.DeclineBooking(uint(bookingID)) // Convert to uint
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	events, err := h.service.GetBookingHistory(uint(bookingID), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBookingError(w, err, "Error retrieving booking history")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Booking declined"})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
 }
 
+// bookingRequestIDs reads the booking ID from the URL and the authenticated
+// user from the context, writing an error response when either is missing.
+func bookingRequestIDs(w http.ResponseWriter, r *http.Request) (bookingID, userID uint, ok bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "bookingID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	userID, ok = middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	return uint(id), userID, true
+}
 
+// writeBookingError maps errors from the booking service to HTTP responses.
+// Errors the service does not define are reported as fallback with a 500.
+func writeBookingError(w http.ResponseWriter, err error, fallback string) {
+	var conflict *service.BookingConflictError
+	var blocked *service.DatesBlockedError
+	var held *service.DatesHeldError
+	var transition *service.InvalidTransitionError
+	var rules *service.BookingRuleError
+	switch {
+	case errors.As(err, &rules):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      err.Error(),
+			"violations": rules.Violations,
+		})
+	case errors.As(err, &conflict), errors.As(err, &blocked), errors.As(err, &held), errors.As(err, &transition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrCarNotFound), errors.Is(err, service.ErrExtraNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrOwnerMustComplete):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrOwnCarBooking):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, service.ErrCarUnavailable), errors.Is(err, service.ErrExtraOutOfStock), errors.Is(err, service.ErrBookingNotDeletable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidBookingDates), errors.Is(err, service.ErrHourGranularity), errors.Is(err, service.ErrInvalidExtraQuantity):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrCarNotPriced):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
 
-func RegisterBookingRoutes(r chi.Router, bookingHandler *BookingHandler) {
+// RegisterBookingRoutes registers the booking routes. idempotent guards
+// booking creation against duplicates from retried requests.
+func RegisterBookingRoutes(r chi.Router, bookingHandler *BookingHandler, idempotent func(http.Handler) http.Handler) {
 	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
-    r.Group(func(public chi.Router) {
-        public.Post("/bookings", bookingHandler.CreateBooking)      // Create booking
-        public.Get("/bookings/{bookingID}", bookingHandler.GetBookingByID) // Get booking by ID
-    })
-
     r.Group(func(protected chi.Router) {
         protected.Use(middleware.AuthMiddleware(jwtSecret))                   // Apply authentication middleware
+        protected.With(idempotent).Post("/bookings", bookingHandler.CreateBooking) // Create booking
+        protected.Get("/bookings/{bookingID}", bookingHandler.GetBookingByID) // Get booking by ID (renter or owner)
         protected.Get("/bookings", bookingHandler.GetBookingsByUserID) // Get bookings for the user
         protected.Put("/bookings/{bookingID}/accept", bookingHandler.AcceptBooking) // Accept booking
         protected.Put("/bookings/{bookingID}/decline", bookingHandler.DeclineBooking) // Decline booking
+        protected.Put("/bookings/{bookingID}/pickup", bookingHandler.PickUpBooking) // Car collected by renter
+        protected.Put("/bookings/{bookingID}/return", bookingHandler.ReturnBooking) // Car returned, booking completed
+        protected.Put("/bookings/{bookingID}/cancel", bookingHandler.CancelBooking) // Cancel booking
+        protected.Get("/bookings/{bookingID}/history", bookingHandler.GetBookingHistory) // Status change timeline
+        protected.Get("/owner/bookings", bookingHandler.GetOwnerBookings) // Bookings on the user's cars
         protected.Delete("/bookings/{bookingID}", bookingHandler.DeleteBooking) // Delete booking
     })
 
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CalendarHandler struct {
+	service *service.CalendarService
+}
+
+func NewCalendarHandler(service *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// RegisterCalendarRoutes registers the car calendar routes with the router.
+// The feed itself is public and protected by the car's secret token instead,
+// since calendar apps cannot send a bearer token.
+func RegisterCalendarRoutes(r chi.Router, handler *CalendarHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Get("/cars/{id}/calendar.ics", handler.ExportCalendar)
+	r.Get("/cars/{id}/availability", handler.GetAvailability)
+
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/cars/{id}/calendar-token", handler.GetCalendarToken)     // Owner only
+		protected.Post("/cars/{id}/calendar-token", handler.RotateCalendarToken) // Owner only
+		protected.Post("/cars/{id}/calendar/import", handler.ImportCalendar)     // Owner only
+	})
+}
+
+func (h *CalendarHandler) ExportCalendar(w http.ResponseWriter, r *http.Request) {
+	carIDStr := chi.URLParam(r, "id")
+	carID, err := strconv.ParseUint(carIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid car ID", http.StatusBadRequest)
+		return
+	}
+
+	cal, err := h.service.ExportCarCalendar(uint(carID), r.URL.Query().Get("token"))
+	if err != nil {
+		writeCalendarError(w, err, "Failed to build calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="car-%d.ics"`, carID))
+	cal.Encode(w)
+}
+
+// GetAvailability reports the car's bookability per day between the from and
+// to query parameters (YYYY-MM-DD, inclusive). Both default to a 30-day
+// window starting today in the car's time zone.
+func (h *CalendarHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	carIDStr := chi.URLParam(r, "id")
+	carID, err := strconv.ParseUint(carIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid car ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := parseDateParam(query.Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from date", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(query.Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid to date", http.StatusBadRequest)
+		return
+	}
+
+	days, err := h.service.GetCarAvailability(uint(carID), from, to)
+	if err != nil {
+		writeCalendarError(w, err, "Failed to retrieve availability")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"car_id": carID,
+		"days":   days,
+	})
+}
+
+// maxCalendarUpload caps the size of an imported iCalendar file.
+const maxCalendarUpload = 2 << 20
+
+// ImportCalendar accepts an .ics file either as the "file" field of a
+// multipart form or as the raw request body. The optional "feed" query
+// parameter names the platform the file comes from; without it the file's
+// PRODID is used.
+func (h *CalendarHandler) ImportCalendar(w http.ResponseWriter, r *http.Request) {
+	carIDStr := chi.URLParam(r, "id")
+	carID, err := strconv.ParseUint(carIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid car ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarUpload)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing calendar file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.service.ImportCarCalendar(uint(carID), userID, r.URL.Query().Get("feed"), body)
+	if err != nil {
+		writeCalendarError(w, err, "Failed to import calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *CalendarHandler) GetCalendarToken(w http.ResponseWriter, r *http.Request) {
+	h.writeToken(w, r, h.service.GetCalendarToken)
+}
+
+func (h *CalendarHandler) RotateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	h.writeToken(w, r, h.service.RotateCalendarToken)
+}
+
+// writeToken responds with the feed token returned by op along with the feed
+// URL owners paste into their calendar app.
+func (h *CalendarHandler) writeToken(w http.ResponseWriter, r *http.Request, op func(carID, userID uint) (string, error)) {
+	carIDStr := chi.URLParam(r, "id")
+	carID, err := strconv.ParseUint(carIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid car ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := op(uint(carID), userID)
+	if err != nil {
+		writeCalendarError(w, err, "Failed to retrieve calendar token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"token":    token,
+		"feed_url": fmt.Sprintf("/cars/%d/calendar.ics?token=%s", carID, token),
+	})
+}
+
+// writeCalendarError maps errors from the calendar service to HTTP responses.
+// Errors the service does not define are reported as fallback with a 500.
+func writeCalendarError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCalendarToken):
+		http.Error(w, "Calendar not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidCalendarFile), errors.Is(err, service.ErrInvalidDateRange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/model"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CarBlockHandler struct {
+	service *service.CarBlockService
+}
+
+func NewCarBlockHandler(service *service.CarBlockService) *CarBlockHandler {
+	return &CarBlockHandler{service: service}
+}
+
+// CarBlockRequest is the payload for creating or changing a block. Dates
+// without a UTC offset are read in the car's time zone.
+type CarBlockRequest struct {
+	StartDate model.LocalTime `json:"start_date"`
+	EndDate   model.LocalTime `json:"end_date"`
+	Reason    string          `json:"reason"`
+}
+
+// RegisterCarBlockRoutes registers the owner-only car block routes.
+func RegisterCarBlockRoutes(r chi.Router, handler *CarBlockHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/cars/{id}/blocks", handler.ListBlocks)
+		protected.Post("/cars/{id}/blocks", handler.CreateBlock)
+		protected.Put("/cars/{id}/blocks/{blockID}", handler.UpdateBlock)
+		protected.Delete("/cars/{id}/blocks/{blockID}", handler.DeleteBlock)
+	})
+}
+
+func (h *CarBlockHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	blocks, err := h.service.ListBlocks(carID, userID)
+	if err != nil {
+		writeCarBlockError(w, err, "Failed to retrieve blocks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+func (h *CarBlockHandler) CreateBlock(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req CarBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	zone, err := h.service.CarZone(carID)
+	if err != nil {
+		writeCarBlockError(w, err, "Failed to create block")
+		return
+	}
+
+	block := model.CarBlock{
+		CarID:     carID,
+		StartDate: req.StartDate.Resolve(zone),
+		EndDate:   req.EndDate.Resolve(zone),
+		Reason:    req.Reason,
+	}
+	if err := h.service.CreateBlock(&block, userID); err != nil {
+		writeCarBlockError(w, err, "Failed to create block")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(block)
+}
+
+func (h *CarBlockHandler) UpdateBlock(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	blockID, err := strconv.ParseUint(chi.URLParam(r, "blockID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CarBlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	zone, err := h.service.CarZone(carID)
+	if err != nil {
+		writeCarBlockError(w, err, "Failed to update block")
+		return
+	}
+
+	block := model.CarBlock{
+		ID:        uint(blockID),
+		CarID:     carID,
+		StartDate: req.StartDate.Resolve(zone),
+		EndDate:   req.EndDate.Resolve(zone),
+		Reason:    req.Reason,
+	}
+	if err := h.service.UpdateBlock(&block, userID); err != nil {
+		writeCarBlockError(w, err, "Failed to update block")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(block)
+}
+
+func (h *CarBlockHandler) DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	blockID, err := strconv.ParseUint(chi.URLParam(r, "blockID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteBlock(carID, uint(blockID), userID); err != nil {
+		writeCarBlockError(w, err, "Failed to delete block")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// carRequestIDs reads the car ID from the URL and the authenticated user from
+// the context, writing an error response when either is missing.
+func carRequestIDs(w http.ResponseWriter, r *http.Request) (carID, userID uint, ok bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid car ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	userID, ok = middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	return uint(id), userID, true
+}
+
+// writeCarBlockError maps errors from the car block service to HTTP
+// responses. Errors the service does not define are reported as fallback
+// with a 500.
+func writeCarBlockError(w http.ResponseWriter, err error, fallback string) {
+	var conflict *service.BookingConflictError
+	switch {
+	case errors.Is(err, service.ErrCarNotFound), errors.Is(err, service.ErrBlockNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidBlockDate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.As(err, &conflict), errors.Is(err, service.ErrImportedBlock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
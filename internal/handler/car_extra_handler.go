@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/model"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CarExtraHandler struct {
+	service *service.CarExtraService
+}
+
+func NewCarExtraHandler(service *service.CarExtraService) *CarExtraHandler {
+	return &CarExtraHandler{service: service}
+}
+
+// CarExtraRequest is the payload for creating or changing an extra.
+type CarExtraRequest struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	PricingType string  `json:"pricing_type"` // "per_day" (default) or "flat"
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+}
+
+// RegisterCarExtraRoutes registers the car extra routes. Anyone may browse a
+// car's extras; only its owner may change them.
+func RegisterCarExtraRoutes(r chi.Router, handler *CarExtraHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Get("/cars/{id}/extras", handler.ListExtras)
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Post("/cars/{id}/extras", handler.CreateExtra)
+		protected.Put("/cars/{id}/extras/{extraID}", handler.UpdateExtra)
+		protected.Delete("/cars/{id}/extras/{extraID}", handler.DeleteExtra)
+	})
+}
+
+func (h *CarExtraHandler) ListExtras(w http.ResponseWriter, r *http.Request) {
+	carID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid car ID", http.StatusBadRequest)
+		return
+	}
+
+	extras, err := h.service.ListExtras(uint(carID))
+	if err != nil {
+		writeCarExtraError(w, err, "Failed to retrieve extras")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(extras)
+}
+
+func (h *CarExtraHandler) CreateExtra(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req CarExtraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	extra := req.toExtra()
+	extra.CarID = carID
+	if err := h.service.CreateExtra(&extra, userID); err != nil {
+		writeCarExtraError(w, err, "Failed to create extra")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(extra)
+}
+
+func (h *CarExtraHandler) UpdateExtra(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	extraID, err := strconv.ParseUint(chi.URLParam(r, "extraID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid extra ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CarExtraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	extra := req.toExtra()
+	extra.ID = uint(extraID)
+	extra.CarID = carID
+	if err := h.service.UpdateExtra(&extra, userID); err != nil {
+		writeCarExtraError(w, err, "Failed to update extra")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(extra)
+}
+
+func (h *CarExtraHandler) DeleteExtra(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	extraID, err := strconv.ParseUint(chi.URLParam(r, "extraID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid extra ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteExtra(carID, uint(extraID), userID); err != nil {
+		writeCarExtraError(w, err, "Failed to delete extra")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (req *CarExtraRequest) toExtra() model.CarExtra {
+	return model.CarExtra{
+		Name:        req.Name,
+		Description: req.Description,
+		PricingType: req.PricingType,
+		Price:       req.Price,
+		Stock:       req.Stock,
+	}
+}
+
+// writeCarExtraError maps errors from the car extra service to HTTP
+// responses. Errors the service does not define are reported as fallback
+// with a 500.
+func writeCarExtraError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCarNotFound), errors.Is(err, service.ErrExtraNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidExtra):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"os"
+	"rentora-go/internal/middleware"
 	"rentora-go/internal/model"
 	"rentora-go/internal/service"
 	"strconv"
@@ -20,21 +23,33 @@ func NewCarHandler(service *service.CarService) *CarHandler {
 
 // RegisterCarRoutes registers the car routes with the router.
 func RegisterCarRoutes(r chi.Router, handler *CarHandler) {
-	r.Post("/cars", handler.CreateCar)
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
 	r.Get("/cars", handler.GetCars)
-	r.Put("/cars/{id}", handler.UpdateCar)
-	r.Delete("/cars/{id}", handler.DeleteCar)
+
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Post("/cars", handler.CreateCar)
+		protected.Put("/cars/{id}", handler.UpdateCar)    // Owner only
+		protected.Delete("/cars/{id}", handler.DeleteCar) // Owner only
+	})
 }
 
 func (h *CarHandler) CreateCar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	var car model.Car
 	if err := json.NewDecoder(r.Body).Decode(&car); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.service.CreateCarListing(&car); err != nil {
-		http.Error(w, "Failed to create car listing", http.StatusInternalServerError)
+	// The owner is always the authenticated user.
+	if err := h.service.CreateCarListing(&car, userID); err != nil {
+		writeCarError(w, err, "Failed to create car listing")
 		return
 	}
 
@@ -62,6 +77,12 @@ func (h *CarHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	var car model.Car
 	if err := json.NewDecoder(r.Body).Decode(&car); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
@@ -71,8 +92,8 @@ func (h *CarHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
 	// Set the car ID from the parsed value
 	car.ID = uint(carID)
 
-	if err := h.service.UpdateCarListing(&car); err != nil {
-		http.Error(w, "Failed to update car listing", http.StatusInternalServerError)
+	if err := h.service.UpdateCarListing(&car, userID); err != nil {
+		writeCarError(w, err, "Failed to update car listing")
 		return
 	}
 
@@ -88,10 +109,34 @@ func (h *CarHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteCarListing(uint(carID)); err != nil {
-		http.Error(w, "Failed to delete car listing", http.StatusInternalServerError)
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.DeleteCarListing(uint(carID), userID); err != nil {
+		writeCarError(w, err, "Failed to delete car listing")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// writeCarError maps errors from the car service to HTTP responses. Errors
+// the service does not define are reported as fallback with a 500.
+func writeCarError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidCancellationPolicy), errors.Is(err, service.ErrInvalidBookingRules),
+		errors.Is(err, service.ErrInvalidUsageFees),
+		errors.Is(err, service.ErrInvalidSecurityDeposit), errors.Is(err, service.ErrInvalidMonthlyDiscount),
+		errors.Is(err, service.ErrInvalidTimeZone), errors.Is(err, service.ErrInvalidHourlyPricing):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
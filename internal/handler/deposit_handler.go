@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DepositHandler struct {
+	service *service.DepositService
+}
+
+func NewDepositHandler(service *service.DepositService) *DepositHandler {
+	return &DepositHandler{service: service}
+}
+
+// SettleDepositRequest is the owner's decision on a held deposit. Leaving
+// WithholdAmount at zero releases the deposit in full.
+type SettleDepositRequest struct {
+	WithholdAmount float64 `json:"withhold_amount"`
+	Reason         string  `json:"reason"`
+}
+
+// RegisterDepositRoutes registers the security deposit routes. Both parties
+// can view the deposit; only the owner can settle it. idempotent guards
+// settlements against retried requests.
+func RegisterDepositRoutes(r chi.Router, handler *DepositHandler, idempotent func(http.Handler) http.Handler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/bookings/{bookingID}/deposit", handler.GetDeposit)
+		protected.With(idempotent).Post("/bookings/{bookingID}/deposit/settle", handler.SettleDeposit)
+	})
+}
+
+func (h *DepositHandler) GetDeposit(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	deposit, err := h.service.GetDeposit(bookingID, userID)
+	if err != nil {
+		writeDepositError(w, err, "Failed to retrieve deposit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deposit)
+}
+
+func (h *DepositHandler) SettleDeposit(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req SettleDepositRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.service.SettleDeposit(bookingID, userID, req.WithholdAmount, req.Reason); err != nil {
+		writeDepositError(w, err, "Failed to settle deposit")
+		return
+	}
+
+	deposit, err := h.service.GetDeposit(bookingID, userID)
+	if err != nil {
+		writeDepositError(w, err, "Failed to retrieve deposit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deposit)
+}
+
+// writeDepositError maps errors from the deposit service to HTTP responses.
+// Errors the service does not define are reported as fallback with a 500.
+func writeDepositError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidWithholding), errors.Is(err, service.ErrWithholdingReason):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrNoDepositHeld), errors.Is(err, service.ErrDepositNotSettleable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
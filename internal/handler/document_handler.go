@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DocumentHandler struct {
+	service *service.DocumentService
+}
+
+func NewDocumentHandler(service *service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{service: service}
+}
+
+// RegisterDocumentRoutes registers the printable booking documents. Both are
+// limited to the booking's renter and owner.
+func RegisterDocumentRoutes(r chi.Router, handler *DocumentHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/bookings/{bookingID}/invoice.pdf", handler.GetInvoice)
+		protected.Get("/bookings/{bookingID}/agreement.pdf", handler.GetAgreement)
+	})
+}
+
+func (h *DocumentHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	document, err := h.service.Invoice(bookingID, userID)
+	if err != nil {
+		writeDocumentError(w, err, "Failed to generate invoice")
+		return
+	}
+	writePDF(w, fmt.Sprintf("invoice-%d.pdf", bookingID), document)
+}
+
+func (h *DocumentHandler) GetAgreement(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	document, err := h.service.Agreement(bookingID, userID)
+	if err != nil {
+		writeDocumentError(w, err, "Failed to generate rental agreement")
+		return
+	}
+	writePDF(w, fmt.Sprintf("agreement-%d.pdf", bookingID), document)
+}
+
+func writePDF(w http.ResponseWriter, filename string, document []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.Write(document)
+}
+
+// writeDocumentError maps errors from the document service to HTTP
+// responses. Errors the service does not define are reported as fallback
+// with a 500.
+func writeDocumentError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrDocumentNotAvailable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/model"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type InspectionHandler struct {
+	service *service.InspectionService
+}
+
+func NewInspectionHandler(service *service.InspectionService) *InspectionHandler {
+	return &InspectionHandler{service: service}
+}
+
+// InspectionRequest is the payload for submitting an inspection.
+type InspectionRequest struct {
+	Odometer        int                   `json:"odometer"`
+	FuelLevel       int                   `json:"fuel_level"`
+	Notes           string                `json:"notes"`
+	DamageChecklist model.DamageChecklist `json:"damage_checklist"`
+}
+
+// RegisterInspectionRoutes registers the pickup and return inspection
+// routes. All of them are limited to the booking's renter and owner.
+func RegisterInspectionRoutes(r chi.Router, handler *InspectionHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/bookings/{bookingID}/inspections", handler.GetInspections)
+		protected.Get("/bookings/{bookingID}/inspections/compare", handler.CompareInspections)
+		protected.Put("/bookings/{bookingID}/inspections/{kind}", handler.SubmitInspection)
+		protected.Post("/bookings/{bookingID}/inspections/{kind}/sign", handler.SignInspection)
+	})
+}
+
+func (h *InspectionHandler) GetInspections(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	inspections, err := h.service.GetInspections(bookingID, userID)
+	if err != nil {
+		writeInspectionError(w, err, "Failed to retrieve inspections")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inspections)
+}
+
+func (h *InspectionHandler) SubmitInspection(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req InspectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	inspection := model.BookingInspection{
+		BookingID:       bookingID,
+		Kind:            chi.URLParam(r, "kind"),
+		Odometer:        req.Odometer,
+		FuelLevel:       req.FuelLevel,
+		Notes:           req.Notes,
+		DamageChecklist: req.DamageChecklist,
+	}
+	if err := h.service.SubmitInspection(&inspection, userID); err != nil {
+		writeInspectionError(w, err, "Failed to submit inspection")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inspection)
+}
+
+func (h *InspectionHandler) SignInspection(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	inspection, err := h.service.SignInspection(bookingID, chi.URLParam(r, "kind"), userID)
+	if err != nil {
+		writeInspectionError(w, err, "Failed to sign inspection")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inspection)
+}
+
+func (h *InspectionHandler) CompareInspections(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.service.CompareInspections(bookingID, userID)
+	if err != nil {
+		writeInspectionError(w, err, "Failed to compare inspections")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// writeInspectionError maps errors from the inspection service to HTTP
+// responses. Errors the service does not define are reported as fallback
+// with a 500.
+func writeInspectionError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrCarNotFound),
+		errors.Is(err, service.ErrInspectionNotFound), errors.Is(err, service.ErrInvalidInspectionKind):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidInspection):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrInspectionSigned), errors.Is(err, service.ErrAlreadySigned),
+		errors.Is(err, service.ErrInspectionNotAllowed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
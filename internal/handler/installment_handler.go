@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type InstallmentHandler struct {
+	service *service.InstallmentService
+}
+
+func NewInstallmentHandler(service *service.InstallmentService) *InstallmentHandler {
+	return &InstallmentHandler{service: service}
+}
+
+// RegisterInstallmentRoutes registers the monthly billing routes of
+// long-term bookings. Both parties can view the schedule; only the renter
+// can pay it. idempotent guards payments against retried requests.
+func RegisterInstallmentRoutes(r chi.Router, handler *InstallmentHandler, idempotent func(http.Handler) http.Handler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/bookings/{bookingID}/installments", handler.GetInstallments)
+		protected.With(idempotent).Post("/bookings/{bookingID}/installments/{installmentID}/pay", handler.PayInstallment)
+	})
+}
+
+func (h *InstallmentHandler) GetInstallments(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	installments, err := h.service.GetInstallments(bookingID, userID)
+	if err != nil {
+		writeInstallmentError(w, err, "Failed to retrieve installments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(installments)
+}
+
+func (h *InstallmentHandler) PayInstallment(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+	installmentID, err := strconv.ParseUint(chi.URLParam(r, "installmentID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid installment ID", http.StatusBadRequest)
+		return
+	}
+
+	installment, err := h.service.PayInstallment(bookingID, uint(installmentID), userID)
+	if err != nil {
+		writeInstallmentError(w, err, "Failed to pay installment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(installment)
+}
+
+// writeInstallmentError maps errors from the installment service to HTTP
+// responses. Errors the service does not define are reported as fallback
+// with a 500.
+func writeInstallmentError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrCarNotFound),
+		errors.Is(err, service.ErrInstallmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInstallmentSettled), errors.Is(err, service.ErrInstallmentNotPayable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/model"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type MessageHandler struct {
+	service *service.MessageService
+}
+
+func NewMessageHandler(service *service.MessageService) *MessageHandler {
+	return &MessageHandler{service: service}
+}
+
+// PostMessageRequest is the payload for posting a message to a booking's
+// thread.
+type PostMessageRequest struct {
+	Body        string                   `json:"body"`
+	Attachments model.MessageAttachments `json:"attachments"`
+}
+
+// RegisterMessageRoutes registers the booking message routes. Threads are
+// limited to the booking's renter and owner.
+func RegisterMessageRoutes(r chi.Router, handler *MessageHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Get("/bookings/{bookingID}/messages", handler.GetThread)
+		protected.Post("/bookings/{bookingID}/messages", handler.PostMessage)
+		protected.Post("/bookings/{bookingID}/messages/read", handler.MarkThreadRead)
+		protected.Get("/messages/unread", handler.GetUnreadCounts)
+	})
+}
+
+// GetThread returns the booking's messages, newest first. It accepts the
+// optional query parameters page and page_size.
+func (h *MessageHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thread, err := h.service.GetThread(bookingID, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		writeMessageError(w, err, "Failed to retrieve messages")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"booking_id": thread.BookingID,
+		"messages":   thread.Messages,
+		"unread":     thread.Unread,
+		"page":       page,
+		"page_size":  pageSize,
+		"total":      thread.Total,
+	})
+}
+
+func (h *MessageHandler) PostMessage(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req PostMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	message := model.BookingMessage{
+		BookingID:   bookingID,
+		Body:        req.Body,
+		Attachments: req.Attachments,
+	}
+	if err := h.service.PostMessage(&message, userID); err != nil {
+		writeMessageError(w, err, "Failed to post message")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+func (h *MessageHandler) MarkThreadRead(w http.ResponseWriter, r *http.Request) {
+	bookingID, userID, ok := bookingRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkThreadRead(bookingID, userID); err != nil {
+		writeMessageError(w, err, "Failed to mark messages read")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MessageHandler) GetUnreadCounts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	counts, err := h.service.GetUnreadCounts(userID)
+	if err != nil {
+		writeMessageError(w, err, "Failed to retrieve unread counts")
+		return
+	}
+
+	var total int64
+	for _, count := range counts {
+		total += count.Unread
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"threads": counts,
+		"total":   total,
+	})
+}
+
+// writeMessageError maps errors from the message service to HTTP responses.
+// Errors the service does not define are reported as fallback with a 500.
+func writeMessageError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrEmptyMessage), errors.Is(err, service.ErrMessageTooLong),
+		errors.Is(err, service.ErrInvalidAttachment):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
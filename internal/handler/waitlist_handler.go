@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"rentora-go/internal/middleware"
+	"rentora-go/internal/model"
+	"rentora-go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WaitlistHandler struct {
+	service *service.WaitlistService
+}
+
+func NewWaitlistHandler(service *service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{service: service}
+}
+
+// JoinWaitlistRequest is the date range a renter wants to wait for. Dates
+// without a UTC offset are read in the car's time zone.
+type JoinWaitlistRequest struct {
+	StartDate model.LocalTime `json:"start_date"`
+	EndDate   model.LocalTime `json:"end_date"`
+}
+
+// RegisterWaitlistRoutes registers the waitlist routes. Renters only see and
+// manage their own entries.
+func RegisterWaitlistRoutes(r chi.Router, handler *WaitlistHandler) {
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+	r.Group(func(protected chi.Router) {
+		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Post("/cars/{id}/waitlist", handler.JoinWaitlist)
+		protected.Get("/waitlist", handler.GetMyEntries)
+		protected.Delete("/waitlist/{entryID}", handler.LeaveWaitlist)
+	})
+}
+
+func (h *WaitlistHandler) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	carID, userID, ok := carRequestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req JoinWaitlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	zone, err := h.service.CarZone(carID)
+	if err != nil {
+		writeWaitlistError(w, err, "Failed to join waitlist")
+		return
+	}
+
+	entry := model.WaitlistEntry{
+		CarID:     carID,
+		UserID:    userID,
+		StartDate: req.StartDate.Resolve(zone),
+		EndDate:   req.EndDate.Resolve(zone),
+	}
+	if err := h.service.JoinWaitlist(&entry); err != nil {
+		writeWaitlistError(w, err, "Failed to join waitlist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (h *WaitlistHandler) GetMyEntries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := h.service.GetMyEntries(userID)
+	if err != nil {
+		writeWaitlistError(w, err, "Failed to retrieve waitlist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (h *WaitlistHandler) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	entryID, err := strconv.ParseUint(chi.URLParam(r, "entryID"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid waitlist entry ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.LeaveWaitlist(uint(entryID), userID); err != nil {
+		writeWaitlistError(w, err, "Failed to leave waitlist")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeWaitlistError maps errors from the waitlist service to HTTP responses.
+// Errors the service does not define are reported as fallback with a 500.
+func writeWaitlistError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCarNotFound), errors.Is(err, service.ErrWaitlistEntryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidBookingDates):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrOwnCarBooking):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, service.ErrDatesAvailable), errors.Is(err, service.ErrAlreadyWaitlisted),
+		errors.Is(err, service.ErrWaitlistEntryClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,115 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) needed to
+// exchange car availability with external calendars.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+
+	// maxLineOctets is the longest a content line may be before folding.
+	maxLineOctets = 75
+)
+
+// Event is a single VEVENT. All-day events use only the date part of Start
+// and End, with End being exclusive as the RFC requires.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Stamp       time.Time
+	Status      string // e.g., "CONFIRMED", "CANCELLED"
+}
+
+// Calendar is a VCALENDAR holding events.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Encode writes the calendar to w with CRLF line endings and long lines
+// folded.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	write := func(line string) {
+		bw.WriteString(fold(line))
+	}
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:" + c.ProdID)
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	if c.Name != "" {
+		write("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	for _, e := range c.Events {
+		write("BEGIN:VEVENT")
+		write("UID:" + escapeText(e.UID))
+		write("DTSTAMP:" + e.Stamp.UTC().Format(dateTimeFormat))
+		if e.AllDay {
+			write("DTSTART;VALUE=DATE:" + e.Start.Format(dateFormat))
+			write("DTEND;VALUE=DATE:" + e.End.Format(dateFormat))
+		} else {
+			write("DTSTART:" + e.Start.UTC().Format(dateTimeFormat))
+			write("DTEND:" + e.End.UTC().Format(dateTimeFormat))
+		}
+		if e.Summary != "" {
+			write("SUMMARY:" + escapeText(e.Summary))
+		}
+		if e.Description != "" {
+			write("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.Status != "" {
+			write("STATUS:" + e.Status)
+		}
+		write("TRANSP:OPAQUE")
+		write("END:VEVENT")
+	}
+	write("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// fold splits a content line into chunks of at most 75 octets, never
+// breaking a UTF-8 sequence, and terminates it with CRLF.
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts as an octet.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
@@ -0,0 +1,274 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrNoCalendar = errors.New("input is not an iCalendar file")
+
+// Parse reads the VEVENTs of an iCalendar stream. Events without a UID or a
+// start are skipped. Properties other than those kept on Event are ignored.
+// Dates and floating times are read as UTC.
+func Parse(r io.Reader) ([]Event, error) {
+	return ParseInLocation(r, time.UTC)
+}
+
+// ParseInLocation is like Parse but reads dates and floating times, those
+// without a TZID or UTC marker, in loc. Times with a TZID that is not a
+// known IANA zone, such as Windows zone names, are read in loc as well.
+func ParseInLocation(r io.Reader, loc *time.Location) ([]Event, error) {
+	cal, err := ParseCalendarInLocation(r, loc)
+	if err != nil {
+		return nil, err
+	}
+	return cal.Events, nil
+}
+
+// ParseCalendarInLocation is like ParseInLocation but also returns the
+// calendar's PRODID and name, which identify the feed it came from.
+func ParseCalendarInLocation(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cal        Calendar
+		current    *Event
+		duration   *eventDuration // DURATION of the current event, used without DTEND
+		inCalendar bool
+		depth      int // nesting inside the current VEVENT, e.g. VALARM
+	)
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			inCalendar = true
+			continue
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && current == nil:
+			current = &Event{}
+			duration = nil
+			continue
+		case name == "BEGIN" && current != nil:
+			depth++
+			continue
+		case name == "END" && current != nil && depth > 0:
+			depth--
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT") && current != nil:
+			if current.UID != "" && !current.Start.IsZero() {
+				switch {
+				case !current.End.IsZero():
+				case duration != nil:
+					current.End = duration.addTo(current.Start)
+				case current.AllDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+				cal.Events = append(cal.Events, *current)
+			}
+			current = nil
+			continue
+		}
+		if current == nil {
+			switch name {
+			case "PRODID":
+				cal.ProdID = value
+			case "X-WR-CALNAME":
+				cal.Name = unescapeText(value)
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			current.UID = value
+		case "SUMMARY":
+			current.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(value)
+		case "STATUS":
+			current.Status = strings.ToUpper(value)
+		case "DTSTAMP":
+			current.Stamp, _ = parseTime(params, value, loc)
+		case "DTSTART":
+			t, err := parseTime(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: invalid DTSTART: %w", current.UID, err)
+			}
+			current.Start = t
+			current.AllDay = isDate(params, value)
+		case "DTEND":
+			t, err := parseTime(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: invalid DTEND: %w", current.UID, err)
+			}
+			current.End = t
+		case "DURATION":
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: invalid DURATION: %w", current.UID, err)
+			}
+			duration = d
+		}
+	}
+
+	if !inCalendar {
+		return nil, ErrNoCalendar
+	}
+	return &cal, nil
+}
+
+// eventDuration is a DURATION value. Days and weeks are nominal, so they
+// keep the wall-clock time across daylight saving changes; the rest is exact.
+type eventDuration struct {
+	days  int
+	exact time.Duration
+}
+
+func (d *eventDuration) addTo(t time.Time) time.Time {
+	return t.AddDate(0, 0, d.days).Add(d.exact)
+}
+
+// parseDuration parses a DURATION value such as "P1W", "P2DT3H" or
+// "-PT15M".
+func parseDuration(value string) (*eventDuration, error) {
+	s := strings.ToUpper(value)
+	sign := 1
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return nil, fmt.Errorf("malformed duration %q", value)
+	}
+	s = s[1:]
+
+	d := &eventDuration{}
+	inTime := false
+	n := -1
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(r-'0')
+			continue
+		case r == 'T' && !inTime && n < 0:
+			inTime = true
+			continue
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("malformed duration %q", value)
+		}
+		switch {
+		case r == 'W' && !inTime:
+			d.days += 7 * n
+		case r == 'D' && !inTime:
+			d.days += n
+		case r == 'H' && inTime:
+			d.exact += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d.exact += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d.exact += time.Duration(n) * time.Second
+		default:
+			return nil, fmt.Errorf("malformed duration %q", value)
+		}
+		n = -1
+	}
+	if n >= 0 {
+		return nil, fmt.Errorf("malformed duration %q", value)
+	}
+	d.days *= sign
+	d.exact *= time.Duration(sign)
+	return d, nil
+}
+
+// unfold joins continuation lines, which start with a space or tab, onto the
+// line before them.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// splitLine splits a content line such as "DTSTART;TZID=Europe/Paris:2025..."
+// into its upper-cased name, its parameters and its value.
+func splitLine(line string) (name string, params map[string]string, value string, ok bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	head, value := line[:colon], line[colon+1:]
+
+	parts := strings.Split(head, ";")
+	name = strings.ToUpper(parts[0])
+	params = make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, found := strings.Cut(p, "="); found {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return name, params, value, true
+}
+
+func isDate(params map[string]string, value string) bool {
+	return strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateFormat)
+}
+
+// parseTime parses DATE and DATE-TIME values. Dates, floating times without
+// a TZID and times whose TZID is not a known zone are read in loc.
+func parseTime(params map[string]string, value string, loc *time.Location) (time.Time, error) {
+	if isDate(params, value) {
+		return time.ParseInLocation(dateFormat, value, loc)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeFormat, value)
+	}
+
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation(strings.TrimSuffix(dateTimeFormat, "Z"), value, loc)
+}
+
+var textUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	`\;`, ";",
+	`\,`, ",",
+	`\n`, "\n",
+	`\N`, "\n",
+)
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,111 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCalendarInLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"PRODID:-//Other Platform//Reservations//EN",
+		"X-WR-CALNAME:Car 42",
+		"BEGIN:VEVENT",
+		"UID:with-end",
+		"DTSTART:20260601T080000Z",
+		"DTEND:20260603T080000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:with-duration",
+		"DTSTART;TZID=Europe/Berlin:20260328T100000",
+		"DURATION:P1DT2H",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:all-day-week",
+		"DTSTART;VALUE=DATE:20260701",
+		"DURATION:P1W",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:windows-zone",
+		"DTSTART;TZID=W. Europe Standard Time:20260801T090000",
+		"DTEND;TZID=W. Europe Standard Time:20260801T170000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:all-day",
+		"DTSTART;VALUE=DATE:20260901",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	cal, err := ParseCalendarInLocation(strings.NewReader(input), berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.ProdID != "-//Other Platform//Reservations//EN" || cal.Name != "Car 42" {
+		t.Errorf("got PRODID %q and name %q", cal.ProdID, cal.Name)
+	}
+
+	want := map[string][2]time.Time{
+		"with-end":      {time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC), time.Date(2026, 6, 3, 8, 0, 0, 0, time.UTC)},
+		"with-duration": {time.Date(2026, 3, 28, 10, 0, 0, 0, berlin), time.Date(2026, 3, 29, 12, 0, 0, 0, berlin)},
+		"all-day-week":  {time.Date(2026, 7, 1, 0, 0, 0, 0, berlin), time.Date(2026, 7, 8, 0, 0, 0, 0, berlin)},
+		"windows-zone":  {time.Date(2026, 8, 1, 9, 0, 0, 0, berlin), time.Date(2026, 8, 1, 17, 0, 0, 0, berlin)},
+		"all-day":       {time.Date(2026, 9, 1, 0, 0, 0, 0, berlin), time.Date(2026, 9, 2, 0, 0, 0, 0, berlin)},
+	}
+	if len(cal.Events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(cal.Events), len(want))
+	}
+	for _, event := range cal.Events {
+		span, ok := want[event.UID]
+		if !ok {
+			t.Errorf("unexpected event %q", event.UID)
+			continue
+		}
+		if !event.Start.Equal(span[0]) || !event.End.Equal(span[1]) {
+			t.Errorf("%s: got %v to %v, want %v to %v", event.UID, event.Start, event.End, span[0], span[1])
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	start := time.Date(2026, 3, 28, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{value: "P1W", want: start.AddDate(0, 0, 7)},
+		{value: "P2D", want: start.AddDate(0, 0, 2)},
+		{value: "PT90M", want: start.Add(90 * time.Minute)},
+		{value: "P1DT2H30M15S", want: start.AddDate(0, 0, 1).Add(2*time.Hour + 30*time.Minute + 15*time.Second)},
+		{value: "+PT1H", want: start.Add(time.Hour)},
+		{value: "-PT15M", want: start.Add(-15 * time.Minute)},
+		{value: "P", err: true},
+		{value: "PT", err: true},
+		{value: "1D", err: true},
+		{value: "P1H", err: true},
+		{value: "PT1D", err: true},
+		{value: "P1", err: true},
+	}
+	for _, tt := range tests {
+		d, err := parseDuration(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseDuration(%q) accepted an invalid duration", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDuration(%q): %v", tt.value, err)
+			continue
+		}
+		if got := d.addTo(start); !got.Equal(tt.want) {
+			t.Errorf("%q from %v = %v, want %v", tt.value, start, got, tt.want)
+		}
+	}
+}
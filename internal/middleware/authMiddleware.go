@@ -51,4 +51,10 @@ func AuthMiddleware(jwtKey []byte) func(http.Handler) http.Handler {
             next.ServeHTTP(w, r.WithContext(ctx))
         })
     }
-}
\ No newline at end of file
+}
+// UserIDFromContext returns the ID of the authenticated user stored by
+// AuthMiddleware.
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value("user_id").(uint)
+	return userID, ok
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// IdempotencyKeyHeader names the request header carrying the client's key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyLease is how long a claimed key may go without a stored
+	// response before the request holding it is presumed dead and a retry
+	// may take over.
+	idempotencyLease = 2 * time.Minute
+)
+
+// Idempotency replays the stored response when an authenticated user repeats
+// a request with the same Idempotency-Key, method and path, so retried POSTs
+// do not create duplicates. Reusing a key with a different body is rejected
+// with 422, and a retry arriving while the first request is still running
+// gets 409. Keys are forgotten after ttl; a key whose request panicked or
+// never stored a response is released straight away or after a short lease.
+// Requests without the header pass straight through. It must run after
+// AuthMiddleware.
+func Idempotency(repo repository.IdempotencyRepository, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			userID, ok := UserIDFromContext(r.Context())
+			if key == "" || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				http.Error(w, "Idempotency-Key must be at most 255 characters", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid request", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+
+			record := &model.IdempotencyRecord{
+				UserID:      userID,
+				Key:         key,
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestHash: hex.EncodeToString(sum[:]),
+			}
+			created, existing, err := claimIdempotencyKey(repo, record, ttl)
+			if err != nil {
+				http.Error(w, "Failed to process Idempotency-Key", http.StatusInternalServerError)
+				return
+			}
+			if !created {
+				replayIdempotentResponse(w, existing, record.RequestHash)
+				return
+			}
+
+			release := func() {
+				if err := repo.DeleteRecord(record.ID); err != nil {
+					log.Printf("Failed to release Idempotency-Key %q: %v", key, err)
+				}
+			}
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// Server errors are not stored, so the client can retry them.
+			if recorder.status >= http.StatusInternalServerError {
+				release()
+				return
+			}
+			record.StatusCode = recorder.status
+			record.ContentType = recorder.Header().Get("Content-Type")
+			record.ResponseBody = recorder.body.Bytes()
+			if err := repo.UpdateRecord(record); err != nil {
+				log.Printf("Failed to store response for Idempotency-Key %q: %v", key, err)
+			}
+		})
+	}
+}
+
+// claimIdempotencyKey stores record for a new key. When the key is already
+// taken it returns the existing record instead, unless that record is older
+// than ttl, or still has no response after idempotencyLease, in which case
+// it is replaced.
+func claimIdempotencyKey(repo repository.IdempotencyRepository, record *model.IdempotencyRecord, ttl time.Duration) (bool, *model.IdempotencyRecord, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := repo.CreateRecord(record)
+		if err != nil || created {
+			return created, nil, err
+		}
+
+		existing, err := repo.GetRecord(record.UserID, record.Key, record.Method, record.Path)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // released by a failed request in the meantime
+		}
+		if err != nil {
+			return false, nil, err
+		}
+		age := time.Since(existing.CreatedAt)
+		abandoned := existing.StatusCode == 0 && age >= idempotencyLease
+		if age < ttl && !abandoned {
+			return false, existing, nil
+		}
+		if err := repo.DeleteRecord(existing.ID); err != nil {
+			return false, nil, err
+		}
+	}
+	return false, nil, errors.New("could not claim idempotency key")
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, record *model.IdempotencyRecord, requestHash string) {
+	switch {
+	case record.RequestHash != requestHash:
+		http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+	case record.StatusCode == 0:
+		http.Error(w, "A request with this Idempotency-Key is still being processed", http.StatusConflict)
+	default:
+		if record.ContentType != "" {
+			w.Header().Set("Content-Type", record.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(record.StatusCode)
+		w.Write(record.ResponseBody)
+	}
+}
+
+// responseRecorder passes a response through to the client while keeping a
+// copy of its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
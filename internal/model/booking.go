@@ -4,15 +4,105 @@ import (
 	"time"
 )
 
+// BookingStatus is the lifecycle state of a booking.
+type BookingStatus string
+
+const (
+	BookingPending   BookingStatus = "Pending"
+	BookingAccepted  BookingStatus = "Accepted"
+	BookingActive    BookingStatus = "Active" // car has been picked up
+	BookingCompleted BookingStatus = "Completed"
+	BookingCancelled BookingStatus = "Cancelled"
+	BookingDeclined  BookingStatus = "Declined"
+	BookingExpired   BookingStatus = "Expired" // owner did not respond in time
+)
+
+// bookingTransitions lists, for each status, the statuses a booking may move
+// to next. Statuses without an entry are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingPending:  {BookingAccepted, BookingDeclined, BookingCancelled, BookingExpired},
+	BookingAccepted: {BookingActive, BookingCancelled},
+	BookingActive:   {BookingCompleted},
+}
+
+// IsValid reports whether s is one of the known booking statuses.
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case BookingPending, BookingAccepted, BookingActive, BookingCompleted,
+		BookingCancelled, BookingDeclined, BookingExpired:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether a booking in status s may move to next.
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Booking struct {
-	ID            uint      `json:"id"`
-	UserID        uint      `json:"user_id"`
-	CarID         uint      `json:"car_id"`
-	StartDate     time.Time `json:"start_date"`
-	EndDate       time.Time `json:"end_date"`
-	TotalAmount   float64   `json:"total_amount"`
-	Status        string    `json:"status"` // e.g., "Pending", "Accepted", "Declined", "Completed", "Cancelled"
-	PaymentMethod string    `json:"payment_method"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint          `json:"id"`
+	UserID        uint          `json:"user_id"`
+	CarID         uint          `json:"car_id"`
+	StartDate     time.Time     `json:"start_date"`
+	EndDate       time.Time     `json:"end_date"`
+	TotalAmount   float64       `json:"total_amount"`
+	Status        BookingStatus `json:"status"`
+	PaymentMethod string        `json:"payment_method"`
+	RefundAmount  float64       `json:"refund_amount"`
+	CancelledAt   *time.Time    `json:"cancelled_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+
+	// Extra charges added on completion, on top of TotalAmount
+	AdjustmentTotal float64 `json:"adjustment_total"`
+
+	// Security Deposit
+	DepositAmount float64 `json:"deposit_amount"`
+	DepositStatus string  `json:"deposit_status"` // empty when no deposit was held
+
+	LineItems   []BookingLineItem   `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"line_items,omitempty"`
+	Adjustments []BookingAdjustment `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"adjustments,omitempty"`
+	// Monthly billing schedule, only for long-term bookings
+	Installments []BookingInstallment `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"installments,omitempty"`
+}
+
+// BookingLineItem is one priced row of a booking's total, calculated by the
+// server when the booking is created.
+type BookingLineItem struct {
+	ID          uint      `json:"id"`
+	BookingID   uint      `gorm:"index" json:"booking_id"`
+	Kind        string    `json:"kind"` // e.g., "rental", "extra"
+	Description string    `json:"description"`
+	Quantity    int       `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price"`
+	Amount      float64   `json:"amount"`
+	CarExtraID  *uint     `json:"car_extra_id,omitempty"` // set on "extra" items
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Adjustment statuses.
+const (
+	AdjustmentCharged  = "charged"
+	AdjustmentDisputed = "disputed" // worked out from readings one party has not confirmed; not charged yet
+)
+
+// BookingAdjustment is an extra charge added to a booking after the rental,
+// such as a late return or mileage overage fee. Only charged adjustments count
+// towards the booking's AdjustmentTotal.
+type BookingAdjustment struct {
+	ID          uint      `json:"id"`
+	BookingID   uint      `gorm:"index" json:"booking_id"`
+	Kind        string    `json:"kind"` // e.g., "late_return", "mileage_overage"
+	Description string    `json:"description"`
+	Quantity    int       `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price"`
+	Amount      float64   `json:"amount"`
+	Status      string    `gorm:"size:20;default:charged" json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
 }
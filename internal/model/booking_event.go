@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// BookingEvent records one status change of a booking, forming its audit
+// timeline.
+type BookingEvent struct {
+	ID         uint          `json:"id"`
+	BookingID  uint          `gorm:"index" json:"booking_id"`
+	ActorID    uint          `json:"actor_id"` // 0 when the system made the change
+	FromStatus BookingStatus `json:"from_status"`
+	ToStatus   BookingStatus `json:"to_status"`
+	Reason     string        `json:"reason"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
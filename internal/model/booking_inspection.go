@@ -0,0 +1,74 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Inspection kinds, one of each per booking.
+const (
+	InspectionPickup = "pickup"
+	InspectionReturn = "return"
+)
+
+// DamageItem is one entry of an inspection's damage checklist.
+type DamageItem struct {
+	Area      string `json:"area"`      // e.g., "front bumper", "windscreen"
+	Condition string `json:"condition"` // e.g., "ok", "scratched", "dented"
+	Notes     string `json:"notes,omitempty"`
+}
+
+// DamageChecklist is stored as a JSON column.
+type DamageChecklist []DamageItem
+
+// Value converts the checklist to a driver-compatible value (gorm.Valuer).
+func (c DamageChecklist) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan assigns a value from the database to the checklist (sql.Scanner).
+func (c *DamageChecklist) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return errors.New("invalid type for DamageChecklist")
+	}
+}
+
+// BookingInspection records the state of the car when it is handed over
+// (pickup) or brought back (return). One party submits it and the other
+// countersigns; once both have signed it can no longer change.
+type BookingInspection struct {
+	ID              uint            `json:"id"`
+	BookingID       uint            `gorm:"uniqueIndex:idx_booking_inspection_kind" json:"booking_id"`
+	Kind            string          `gorm:"size:10;uniqueIndex:idx_booking_inspection_kind" json:"kind"`
+	Odometer        int             `json:"odometer"`   // kilometres
+	FuelLevel       int             `json:"fuel_level"` // percent of tank or battery charge
+	Notes           string          `json:"notes"`
+	DamageChecklist DamageChecklist `gorm:"type:json" json:"damage_checklist"`
+	SubmittedBy     uint            `json:"submitted_by"`
+	RenterSignedAt  *time.Time      `json:"renter_signed_at"`
+	OwnerSignedAt   *time.Time      `json:"owner_signed_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// IsCountersigned reports whether both the renter and the owner signed.
+func (i *BookingInspection) IsCountersigned() bool {
+	return i.RenterSignedAt != nil && i.OwnerSignedAt != nil
+}
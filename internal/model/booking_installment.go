@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Installment statuses.
+const (
+	InstallmentPending   = "pending"
+	InstallmentPaid      = "paid"
+	InstallmentOverdue   = "overdue"
+	InstallmentCancelled = "cancelled" // the booking did not go ahead
+)
+
+// BookingInstallment is one monthly payment of a long-term booking. Together
+// a booking's installments add up to its TotalAmount.
+type BookingInstallment struct {
+	ID          uint       `json:"id"`
+	BookingID   uint       `gorm:"index" json:"booking_id"`
+	Sequence    int        `json:"sequence"` // 1 for the first month
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"`
+	DueDate     time.Time  `json:"due_date"`
+	Amount      float64    `json:"amount"`
+	Status      string     `gorm:"size:20;default:pending" json:"status"`
+	PaidAt      *time.Time `json:"paid_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
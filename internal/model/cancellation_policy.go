@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// CancellationPolicy decides how much of a booking's total is refunded when
+// the renter cancels. Owners choose one per car.
+type CancellationPolicy string
+
+const (
+	CancellationFlexible CancellationPolicy = "flexible"
+	CancellationModerate CancellationPolicy = "moderate"
+	CancellationStrict   CancellationPolicy = "strict"
+)
+
+// refundTier refunds Percent of the total when the renter cancels at least
+// MinNotice before the booking starts.
+type refundTier struct {
+	MinNotice time.Duration
+	Percent   float64
+}
+
+// refundTiers are ordered from the longest notice to the shortest. Cancelling
+// with less notice than the last tier refunds nothing.
+var refundTiers = map[CancellationPolicy][]refundTier{
+	CancellationFlexible: {
+		{MinNotice: 24 * time.Hour, Percent: 100},
+		{MinNotice: 0, Percent: 50},
+	},
+	CancellationModerate: {
+		{MinNotice: 5 * 24 * time.Hour, Percent: 100},
+		{MinNotice: 24 * time.Hour, Percent: 50},
+	},
+	CancellationStrict: {
+		{MinNotice: 7 * 24 * time.Hour, Percent: 50},
+	},
+}
+
+// IsValid reports whether p is one of the known policies.
+func (p CancellationPolicy) IsValid() bool {
+	_, ok := refundTiers[p]
+	return ok
+}
+
+// RefundPercent returns the percentage of the total refunded when the renter
+// cancels with the given notice before the start of the booking.
+func (p CancellationPolicy) RefundPercent(notice time.Duration) float64 {
+	for _, tier := range refundTiers[p] {
+		if notice >= tier.MinNotice {
+			return tier.Percent
+		}
+	}
+	return 0
+}
+
+// Terms describes the policy's refund tiers in plain words, one line per
+// tier, for printed agreements.
+func (p CancellationPolicy) Terms() []string {
+	var terms []string
+	tiers := refundTiers[p]
+	for _, tier := range tiers {
+		if tier.MinNotice == 0 {
+			terms = append(terms, fmt.Sprintf("%g%% refund when cancelled any time before pickup", tier.Percent))
+			return terms
+		}
+		terms = append(terms, fmt.Sprintf("%g%% refund when cancelled at least %s before pickup", tier.Percent, noticeText(tier.MinNotice)))
+	}
+	return append(terms, "No refund when cancelled with less notice")
+}
+
+func noticeText(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		if days := int(d / (24 * time.Hour)); days != 1 {
+			return fmt.Sprintf("%d days", days)
+		}
+		return "1 day"
+	}
+	return fmt.Sprintf("%g hours", d.Hours())
+}
@@ -16,5 +16,46 @@ type Car struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 
+	// Booking Policies
+	CancellationPolicy CancellationPolicy `gorm:"default:flexible" json:"cancellation_policy"`
+	InstantBook        bool               `gorm:"default:false" json:"instant_book"` // confirm eligible bookings without owner approval
+	SecurityDeposit    float64            `gorm:"default:0" json:"security_deposit"` // held from acceptance until after return
+
+	// Booking Rules (0 means no limit)
+	MinRentalDays         int `gorm:"default:1" json:"min_rental_days"`
+	MaxRentalDays         int `gorm:"default:0" json:"max_rental_days"`
+	AdvanceNoticeHours    int `gorm:"default:0" json:"advance_notice_hours"`    // how long before the start a booking must be made
+	TurnaroundBufferHours int `gorm:"default:0" json:"turnaround_buffer_hours"` // gap kept free between consecutive bookings
+
+	// Usage Fees (0 means not charged)
+	MileageAllowancePerDay int     `gorm:"default:0" json:"mileage_allowance_per_day"` // kilometres included per rental day
+	OverageRatePerKm       float64 `gorm:"default:0" json:"overage_rate_per_km"`
+	LateReturnGraceMinutes int     `gorm:"default:0" json:"late_return_grace_minutes"`
+	LateFeePerHour         float64 `gorm:"default:0" json:"late_fee_per_hour"`
+
+	// Hourly Pricing (0 means the car is not rented by the hour)
+	PricePerHour float64 `gorm:"default:0" json:"price_per_hour"`
+	DailyCap     float64 `gorm:"default:0" json:"daily_cap"` // most a day of hourly charges can cost
+
+	// Long-Term Rentals (bookings over 30 days, billed monthly)
+	MonthlyDiscountPercent float64 `gorm:"default:0" json:"monthly_discount_percent"`
+
+	// IANA time zone of the car's location, e.g. "Europe/Berlin". Booking
+	// days are counted in this zone; times are stored in UTC.
+	TimeZone string `gorm:"size:64;default:UTC" json:"time_zone"`
+
+	// Secret that grants read access to the car's iCalendar feed
+	CalendarToken string `gorm:"size:64" json:"-"`
+
 	Owner User `gorm:"foreignKey:OwnerID" json:"owner"`
 }
+
+// Zone returns the car's time zone, falling back to UTC when it is unset or
+// unknown.
+func (c *Car) Zone() *time.Location {
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
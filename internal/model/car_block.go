@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Where a car block came from.
+const (
+	BlockSourceOwner  = "owner" // created by the owner in Rentora
+	BlockSourceImport = "ical"  // imported from an external calendar
+)
+
+// CarBlock marks a period during which a car cannot be booked, such as
+// personal use, maintenance or a reservation made on another platform.
+type CarBlock struct {
+	ID           uint      `json:"id"`
+	CarID        uint      `gorm:"index" json:"car_id"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"` // exclusive
+	Reason       string    `json:"reason"`
+	Source       string    `gorm:"size:20;default:owner" json:"source"`
+	ExternalUID  string    `gorm:"size:255;index" json:"external_uid,omitempty"`  // UID of the imported VEVENT
+	ExternalFeed string    `gorm:"size:255;index" json:"external_feed,omitempty"` // import name given by the owner, or the file's PRODID
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// How a car extra is charged.
+const (
+	ExtraPerDay = "per_day" // price times the rental days
+	ExtraFlat   = "flat"    // price once per booking
+)
+
+// CarExtra is an add-on the owner offers with a car, such as a child seat,
+// GPS, an extra driver or roadside cover.
+type CarExtra struct {
+	ID          uint      `json:"id"`
+	CarID       uint      `gorm:"index" json:"car_id"`
+	Name        string    `gorm:"size:100" json:"name"`
+	Description string    `json:"description"`
+	PricingType string    `gorm:"size:20;default:per_day" json:"pricing_type"`
+	Price       float64   `json:"price"` // per unit
+	Stock       int       `json:"stock"` // units the owner has; 0 means none left to offer
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
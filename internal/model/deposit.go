@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Deposit statuses of a booking.
+const (
+	DepositHeld              = "held"
+	DepositReleased          = "released"
+	DepositPartiallyWithheld = "partially_withheld"
+	DepositWithheld          = "withheld"
+)
+
+// Deposit ledger entry types.
+const (
+	DepositEntryHold     = "hold"
+	DepositEntryRelease  = "release"
+	DepositEntryWithhold = "withhold"
+)
+
+// DepositLedgerEntry records one movement of a booking's security deposit.
+// Entries are only ever appended.
+type DepositLedgerEntry struct {
+	ID        uint      `json:"id"`
+	BookingID uint      `gorm:"index" json:"booking_id"`
+	Type      string    `gorm:"size:20" json:"type"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	ActorID   uint      `json:"actor_id"` // 0 when the system made the entry
+	CreatedAt time.Time `json:"created_at"`
+}
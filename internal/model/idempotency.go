@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// IdempotencyRecord stores the response to a request made with an
+// Idempotency-Key header so a retry of the same request gets the same
+// response instead of repeating its effects. Keys are scoped to the user,
+// method and path. StatusCode stays 0 while the first request is running.
+type IdempotencyRecord struct {
+	ID           uint      `json:"id"`
+	UserID       uint      `gorm:"uniqueIndex:idx_idempotency_scope" json:"user_id"`
+	Key          string    `gorm:"size:255;uniqueIndex:idx_idempotency_scope" json:"key"`
+	Method       string    `gorm:"size:10;uniqueIndex:idx_idempotency_scope" json:"method"`
+	Path         string    `gorm:"size:255;uniqueIndex:idx_idempotency_scope" json:"path"`
+	RequestHash  string    `gorm:"size:64" json:"request_hash"` // SHA-256 of the request body
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type"`
+	ResponseBody []byte    `gorm:"type:mediumblob" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
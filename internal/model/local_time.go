@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// localTimeLayouts are the accepted forms of a wall-clock time without a UTC
+// offset, most specific first.
+var localTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", time.DateOnly}
+
+// LocalTime is a date and time sent by a client for a car. A value with a UTC
+// offset (RFC 3339) names an exact instant. A value without one, such as
+// "2025-06-01T10:00" or "2025-06-01", is a wall-clock time in the car's time
+// zone and only becomes an instant once resolved against that zone.
+type LocalTime struct {
+	value    time.Time
+	floating bool
+}
+
+// UnmarshalJSON accepts RFC 3339, YYYY-MM-DDTHH:MM[:SS] or YYYY-MM-DD.
+func (t *LocalTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*t = LocalTime{}
+		return nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, s); err == nil {
+		*t = LocalTime{value: parsed}
+		return nil
+	}
+	for _, layout := range localTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = LocalTime{value: parsed, floating: true}
+			return nil
+		}
+	}
+	return errors.New("invalid time, use RFC 3339, YYYY-MM-DDTHH:MM or YYYY-MM-DD")
+}
+
+// Resolve returns the instant t names in loc, in UTC. The zero LocalTime
+// resolves to the zero time.
+func (t LocalTime) Resolve(loc *time.Location) time.Time {
+	if t.value.IsZero() {
+		return time.Time{}
+	}
+	if !t.floating {
+		return t.value.UTC()
+	}
+	v := t.value
+	return time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), 0, loc).UTC()
+}
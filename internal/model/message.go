@@ -0,0 +1,68 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// MessageAttachment describes a file shared in a message thread. Only the
+// metadata is stored; the file itself lives wherever URL points.
+type MessageAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"` // bytes
+	URL         string `json:"url"`
+}
+
+// MessageAttachments is stored as a JSON column.
+type MessageAttachments []MessageAttachment
+
+// Value converts the attachments to a driver-compatible value (gorm.Valuer).
+func (a MessageAttachments) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan assigns a value from the database to the attachments (sql.Scanner).
+func (a *MessageAttachments) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("invalid type for MessageAttachments")
+	}
+}
+
+// BookingMessage is one message in the thread between a booking's renter and
+// the car's owner. Each booking has exactly one thread.
+type BookingMessage struct {
+	ID          uint               `json:"id"`
+	BookingID   uint               `gorm:"index" json:"booking_id"`
+	SenderID    uint               `json:"sender_id"`
+	Body        string             `gorm:"type:text" json:"body"`
+	Attachments MessageAttachments `gorm:"type:json" json:"attachments"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// MessageReadMarker records the last message a user has read in a booking's
+// thread. Later messages from the other party count as unread.
+type MessageReadMarker struct {
+	ID                uint      `json:"id"`
+	BookingID         uint      `gorm:"uniqueIndex:idx_message_read_booking_user" json:"booking_id"`
+	UserID            uint      `gorm:"uniqueIndex:idx_message_read_booking_user" json:"user_id"`
+	LastReadMessageID uint      `json:"last_read_message_id"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// SchemaMigration records a one-off data migration that has been applied, so
+// it never runs twice.
+type SchemaMigration struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`
+	AppliedAt time.Time `gorm:"autoCreateTime" json:"applied_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Waitlist entry statuses.
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"   // the dates came free and are held for the renter
+	WaitlistBooked    = "booked"    // the renter booked during the hold
+	WaitlistExpired   = "expired"   // the hold ran out unused
+	WaitlistCancelled = "cancelled" // the renter left the waitlist
+)
+
+// WaitlistEntry is a renter waiting for a car's dates to come free. Entries
+// are offered in the order they joined.
+type WaitlistEntry struct {
+	ID            uint       `json:"id"`
+	CarID         uint       `gorm:"index" json:"car_id"`
+	UserID        uint       `gorm:"index" json:"user_id"`
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       time.Time  `json:"end_date"`
+	Status        string     `gorm:"size:20;default:waiting" json:"status"`
+	HoldExpiresAt *time.Time `json:"hold_expires_at"` // set once the dates are offered
+	BookingID     *uint      `json:"booking_id"`      // set once the renter books
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package pdf
+
+// helveticaWidths holds the Helvetica advance widths, in thousandths of the
+// font size, of the printable ASCII characters starting at space.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0 to 9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A to M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N to Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a to m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n to z
+	334, 260, 334, 584, // { to ~
+}
+
+// TextWidth estimates the width in points of s set in Helvetica at size.
+// Bold text runs slightly wider; the difference does not matter for the
+// right-aligned figures this is used for.
+func TextWidth(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
@@ -0,0 +1,134 @@
+// Package pdf writes simple single-column PDF documents: text in the
+// standard Helvetica fonts and straight lines, which is all the booking
+// invoices and rental agreements need.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+// Page is one page of a Document. Coordinates are in points from the top-left
+// corner, with y pointing down to the text baseline.
+type Page struct {
+	content bytes.Buffer
+}
+
+// Document is a PDF being built page by page.
+type Document struct {
+	Title string
+	pages []*Page
+}
+
+func New(title string) *Document {
+	return &Document{Title: title}
+}
+
+// AddPage starts a new A4 page.
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (p *Page) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escape(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (p *Page) TextRight(x, y, size float64, bold bool, s string) {
+	p.Text(x-TextWidth(s, size), y, size, bold, s)
+}
+
+// Line draws a thin line from (x1, y1) to (x2, y2).
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are fixed; each page then takes two objects, the page
+	// itself and its content stream.
+	const firstPage = 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+2*i+1))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+	}
+
+	info := len(offsets) + 1
+	object(fmt.Sprintf("<< /Title (%s) /Producer (rentora) >>", escape(d.Title)))
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+
+	return out.WriteTo(w)
+}
+
+// escape converts s to WinAnsi and escapes it for use in a PDF string.
+// Characters the standard fonts cannot show are replaced with '?'.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '€':
+			b.WriteString(`\200`)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteToCrossReference(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages int
+	}{
+		{"no pages", 0},
+		{"one page", 1},
+		{"several pages", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New("Invoice (INV-000001)")
+			for i := 0; i < tt.pages; i++ {
+				page := doc.AddPage()
+				page.Text(50, 60, 10, i%2 == 0, fmt.Sprintf("Page %d (of %d) \\ €5", i+1, tt.pages))
+				page.Line(50, 70, 545, 70)
+			}
+
+			var buf bytes.Buffer
+			n, err := doc.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := buf.Bytes()
+			if n != int64(len(out)) {
+				t.Errorf("WriteTo reported %d bytes, wrote %d", n, len(out))
+			}
+
+			objects := 4 + 2*tt.pages + 1
+			offsets := parseXref(t, out, objects)
+			for i, offset := range offsets {
+				want := fmt.Sprintf("%d 0 obj\n", i+1)
+				if !bytes.HasPrefix(out[offset:], []byte(want)) {
+					t.Errorf("xref entry %d points at %q, want %q", i+1, head(out[offset:]), want)
+				}
+			}
+
+			if !bytes.Contains(out, []byte(fmt.Sprintf("/Count %d", tt.pages))) {
+				t.Errorf("page tree does not count %d pages", tt.pages)
+			}
+			if !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+				t.Errorf("document does not end with %%%%EOF")
+			}
+		})
+	}
+}
+
+func TestWriteToContentStream(t *testing.T) {
+	doc := New("Test")
+	doc.AddPage().Text(50, 60, 10, false, "Total (incl. tax)")
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	match := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n(.*)\nendstream`).FindSubmatch(buf.Bytes())
+	if match == nil {
+		t.Fatal("no content stream found")
+	}
+	length, _ := strconv.Atoi(string(match[1]))
+	if length != len(match[2]) {
+		t.Errorf("stream /Length is %d, stream holds %d bytes", length, len(match[2]))
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(match[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `(Total \(incl. tax\)) Tj`; !strings.Contains(string(content), want) {
+		t.Errorf("content %q does not contain %q", content, want)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{`a (b) \c`, `a \(b\) \\c`},
+		{"€10", `\20010`},
+		{"Müller", `M\374ller`},
+		{"日本", "??"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// parseXref checks the trailer's startxref and returns the offsets of the
+// in-use objects listed in the cross-reference table.
+func parseXref(t *testing.T, out []byte, objects int) []int {
+	t.Helper()
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	if match == nil {
+		t.Fatal("no startxref found")
+	}
+	start, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(out[start:], []byte("xref\n")) {
+		t.Fatalf("startxref %d points at %q", start, head(out[start:]))
+	}
+
+	lines := strings.Split(string(out[start:]), "\n")
+	if want := fmt.Sprintf("0 %d", objects+1); lines[1] != want {
+		t.Fatalf("xref subsection %q, want %q", lines[1], want)
+	}
+	if !strings.Contains(string(out), fmt.Sprintf("/Size %d", objects+1)) {
+		t.Errorf("trailer /Size does not match %d entries", objects+1)
+	}
+
+	var offsets []int
+	for _, line := range lines[3 : 3+objects] {
+		if len(line) != 19 || !strings.HasSuffix(line, " 00000 n ") {
+			t.Fatalf("malformed xref entry %q", line)
+		}
+		offset, err := strconv.Atoi(line[:10])
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets
+}
+
+func head(b []byte) string {
+	if len(b) > 20 {
+		b = b[:20]
+	}
+	return string(b)
+}
@@ -1,16 +1,71 @@
 package repository
 
 import (
+	"time"
+
 	"rentora-go/internal/model"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// BookingFilter narrows down a booking listing. Zero-valued fields are not
+// applied.
+type BookingFilter struct {
+	Status model.BookingStatus
+	CarID  uint
+	From   time.Time // only bookings ending after From
+	To     time.Time // only bookings starting before To
+	Limit  int
+	Offset int
+}
+
 type BookingRepository interface {
 	CreateBooking(booking *model.Booking) error
 	GetBookingsByUserID(userID uint) ([]model.Booking, error)
+	// GetBookingsByOwnerID lists bookings on cars owned by ownerID, newest
+	// first, along with the total number of matches before pagination.
+	GetBookingsByOwnerID(ownerID uint, filter BookingFilter) ([]model.Booking, int64, error)
 	GetBookingByID(bookingID uint) (*model.Booking, error)
 	UpdateBooking(booking *model.Booking) error
 	DeleteBooking(bookingID uint) error
+
+	// Transaction runs fn against a repository bound to a single database
+	// transaction. The transaction is rolled back if fn returns an error.
+	Transaction(fn func(repo BookingRepository) error) error
+	// GetCarForUpdate loads a car and locks its row until the surrounding
+	// transaction ends, serializing concurrent bookings for the same car.
+	GetCarForUpdate(carID uint) (*model.Car, error)
+	GetBookingForUpdate(bookingID uint) (*model.Booking, error)
+	FindOverlappingBookings(carID uint, start, end time.Time, statuses []model.BookingStatus) ([]model.Booking, error)
+	// FindOverlappingBlocks returns the car's blocks intersecting [start, end).
+	FindOverlappingBlocks(carID uint, start, end time.Time) ([]model.CarBlock, error)
+	// Blocks returns a block repository sharing this repository's
+	// transaction.
+	Blocks() CarBlockRepository
+	// Deposits returns a deposit repository sharing this repository's
+	// transaction.
+	Deposits() DepositRepository
+	// Installments returns an installment repository sharing this
+	// repository's transaction.
+	Installments() InstallmentRepository
+	// Waitlist returns a waitlist repository sharing this repository's
+	// transaction.
+	Waitlist() WaitlistRepository
+	// Extras returns a car extra repository sharing this repository's
+	// transaction.
+	Extras() CarExtraRepository
+	// Inspections returns an inspection repository sharing this
+	// repository's transaction.
+	Inspections() InspectionRepository
+	GetPendingBookingsCreatedBefore(cutoff time.Time) ([]model.Booking, error)
+	// GetCarBookings lists the car's bookings in the given statuses that end
+	// after since, ordered by start date.
+	GetCarBookings(carID uint, statuses []model.BookingStatus, since time.Time) ([]model.Booking, error)
+
+	CreateBookingAdjustment(adjustment *model.BookingAdjustment) error
+	CreateBookingEvent(event *model.BookingEvent) error
+	GetBookingEvents(bookingID uint) ([]model.BookingEvent, error)
 }
 
 type bookingRepository struct {
@@ -30,15 +85,50 @@ func (r *bookingRepository) CreateBooking(booking *model.Booking) error {
 
 func (r *bookingRepository) GetBookingsByUserID(userID uint) ([]model.Booking, error) {
 	var bookings []model.Booking
-	if err := r.db.Where("user_id = ?", userID).Find(&bookings).Error; err != nil {
+	if err := r.db.Preload("LineItems").Preload("Adjustments").Where("user_id = ?", userID).Find(&bookings).Error; err != nil {
 		return nil, err
 	}
 	return bookings, nil
 }
 
+func (r *bookingRepository) GetBookingsByOwnerID(ownerID uint, filter BookingFilter) ([]model.Booking, int64, error) {
+	query := r.db.Model(&model.Booking{}).
+		Joins("JOIN cars ON cars.id = bookings.car_id").
+		Where("cars.owner_id = ?", ownerID)
+	if filter.Status != "" {
+		query = query.Where("bookings.status = ?", filter.Status)
+	}
+	if filter.CarID != 0 {
+		query = query.Where("bookings.car_id = ?", filter.CarID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("bookings.end_date > ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("bookings.start_date < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var bookings []model.Booking
+	err := query.Select("bookings.*").
+		Preload("LineItems").Preload("Adjustments").
+		Order("bookings.created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return bookings, total, nil
+}
+
 func (r *bookingRepository) GetBookingByID(bookingID uint) (*model.Booking, error) {
 	var booking model.Booking
-	if err := r.db.First(&booking, bookingID).Error; err != nil {
+	if err := r.db.Preload("LineItems").Preload("Adjustments").First(&booking, bookingID).Error; err != nil {
 		return nil, err
 	}
 	return &booking, nil
@@ -57,3 +147,115 @@ func (r *bookingRepository) DeleteBooking(bookingID uint) error {
 	}
 	return nil
 }
+
+func (r *bookingRepository) Transaction(fn func(repo BookingRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&bookingRepository{db: tx})
+	})
+}
+
+func (r *bookingRepository) GetCarForUpdate(carID uint) (*model.Car, error) {
+	var car model.Car
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, carID).Error; err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+func (r *bookingRepository) GetBookingForUpdate(bookingID uint) (*model.Booking, error) {
+	var booking model.Booking
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// FindOverlappingBookings returns bookings for the car in one of the given
+// statuses whose [start_date, end_date) range intersects [start, end).
+func (r *bookingRepository) FindOverlappingBookings(carID uint, start, end time.Time, statuses []model.BookingStatus) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("car_id = ? AND status IN ? AND start_date < ? AND end_date > ?", carID, statuses, end, start).
+		Order("start_date").
+		Find(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *bookingRepository) FindOverlappingBlocks(carID uint, start, end time.Time) ([]model.CarBlock, error) {
+	var blocks []model.CarBlock
+	err := r.db.Where("car_id = ? AND start_date < ? AND end_date > ?", carID, end, start).
+		Order("start_date").
+		Find(&blocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (r *bookingRepository) Blocks() CarBlockRepository {
+	return &carBlockRepository{db: r.db}
+}
+
+func (r *bookingRepository) Deposits() DepositRepository {
+	return &depositRepository{db: r.db}
+}
+
+func (r *bookingRepository) Installments() InstallmentRepository {
+	return &installmentRepository{db: r.db}
+}
+
+func (r *bookingRepository) Waitlist() WaitlistRepository {
+	return &waitlistRepository{db: r.db}
+}
+
+func (r *bookingRepository) Extras() CarExtraRepository {
+	return &carExtraRepository{db: r.db}
+}
+
+func (r *bookingRepository) Inspections() InspectionRepository {
+	return &inspectionRepository{db: r.db}
+}
+
+func (r *bookingRepository) GetPendingBookingsCreatedBefore(cutoff time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+	if err := r.db.Where("status = ? AND created_at < ?", model.BookingPending, cutoff).Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *bookingRepository) GetCarBookings(carID uint, statuses []model.BookingStatus, since time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Where("car_id = ? AND status IN ? AND end_date > ?", carID, statuses, since).
+		Order("start_date").
+		Find(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *bookingRepository) CreateBookingAdjustment(adjustment *model.BookingAdjustment) error {
+	if err := r.db.Create(adjustment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *bookingRepository) CreateBookingEvent(event *model.BookingEvent) error {
+	if err := r.db.Create(event).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *bookingRepository) GetBookingEvents(bookingID uint) ([]model.BookingEvent, error) {
+	var events []model.BookingEvent
+	if err := r.db.Where("booking_id = ?", bookingID).Order("created_at, id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CarBlockRepository interface {
+	CreateBlock(block *model.CarBlock) error
+	GetBlockByID(blockID uint) (*model.CarBlock, error)
+	// GetImportedBlocks lists every block on the car that came from an
+	// imported calendar.
+	GetImportedBlocks(carID uint) ([]model.CarBlock, error)
+	// GetBlocksByCarID lists the car's blocks that end after since, ordered
+	// by start date.
+	GetBlocksByCarID(carID uint, since time.Time) ([]model.CarBlock, error)
+	UpdateBlock(block *model.CarBlock) error
+	DeleteBlock(blockID uint) error
+}
+
+type carBlockRepository struct {
+	db *gorm.DB
+}
+
+func NewCarBlockRepository(db *gorm.DB) CarBlockRepository {
+	return &carBlockRepository{db: db}
+}
+
+func (r *carBlockRepository) CreateBlock(block *model.CarBlock) error {
+	if err := r.db.Create(block).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *carBlockRepository) GetBlockByID(blockID uint) (*model.CarBlock, error) {
+	var block model.CarBlock
+	if err := r.db.First(&block, blockID).Error; err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *carBlockRepository) GetImportedBlocks(carID uint) ([]model.CarBlock, error) {
+	var blocks []model.CarBlock
+	if err := r.db.Where("car_id = ? AND source = ?", carID, model.BlockSourceImport).Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (r *carBlockRepository) GetBlocksByCarID(carID uint, since time.Time) ([]model.CarBlock, error) {
+	var blocks []model.CarBlock
+	if err := r.db.Where("car_id = ? AND end_date > ?", carID, since).Order("start_date").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (r *carBlockRepository) UpdateBlock(block *model.CarBlock) error {
+	if err := r.db.Save(block).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *carBlockRepository) DeleteBlock(blockID uint) error {
+	if err := r.db.Delete(&model.CarBlock{}, blockID).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CarExtraRepository interface {
+	CreateExtra(extra *model.CarExtra) error
+	GetExtraByID(extraID uint) (*model.CarExtra, error)
+	// GetExtrasByCarID lists the car's extras ordered by name.
+	GetExtrasByCarID(carID uint) ([]model.CarExtra, error)
+	UpdateExtra(extra *model.CarExtra) error
+	DeleteExtra(extraID uint) error
+}
+
+type carExtraRepository struct {
+	db *gorm.DB
+}
+
+func NewCarExtraRepository(db *gorm.DB) CarExtraRepository {
+	return &carExtraRepository{db: db}
+}
+
+func (r *carExtraRepository) CreateExtra(extra *model.CarExtra) error {
+	if err := r.db.Create(extra).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *carExtraRepository) GetExtraByID(extraID uint) (*model.CarExtra, error) {
+	var extra model.CarExtra
+	if err := r.db.First(&extra, extraID).Error; err != nil {
+		return nil, err
+	}
+	return &extra, nil
+}
+
+func (r *carExtraRepository) GetExtrasByCarID(carID uint) ([]model.CarExtra, error) {
+	var extras []model.CarExtra
+	if err := r.db.Where("car_id = ?", carID).Order("name").Find(&extras).Error; err != nil {
+		return nil, err
+	}
+	return extras, nil
+}
+
+func (r *carExtraRepository) UpdateExtra(extra *model.CarExtra) error {
+	if err := r.db.Save(extra).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *carExtraRepository) DeleteExtra(extraID uint) error {
+	if err := r.db.Delete(&model.CarExtra{}, extraID).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -3,10 +3,12 @@ package repository
 import (
 	"rentora-go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CarRepository interface {
 	CreateCar(car *model.Car) error
+	GetCarByID(carID uint) (*model.Car, error)
 	GetAvailableCars(location string) ([]model.Car, error)
 	UpdateCar(car *model.Car) error
 	DeleteCar(carID uint) error
@@ -20,13 +22,23 @@ func NewCarRepository(db *gorm.DB) CarRepository {
 	return &carRepository{db: db}
 }
 
+// CreateCar stores the car row only. Associations such as Owner are never
+// written, so a decoded payload cannot create users or reassign OwnerID.
 func (r *carRepository) CreateCar(car *model.Car) error {
-	if err := r.db.Create(car).Error; err != nil {
+	if err := r.db.Omit(clause.Associations).Create(car).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+func (r *carRepository) GetCarByID(carID uint) (*model.Car, error) {
+	var car model.Car
+	if err := r.db.First(&car, carID).Error; err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
 func (r *carRepository) GetAvailableCars(location string) ([]model.Car, error) {
 	var cars []model.Car
 	if err := r.db.Where("availability = ? AND location = ?", true, location).Find(&cars).Error; err != nil {
@@ -35,8 +47,9 @@ func (r *carRepository) GetAvailableCars(location string) ([]model.Car, error) {
 	return cars, nil
 }
 
+// UpdateCar saves the car row only; see CreateCar.
 func (r *carRepository) UpdateCar(car *model.Car) error {
-	if err := r.db.Save(car).Error; err != nil {
+	if err := r.db.Omit(clause.Associations).Save(car).Error; err != nil {
 		return err
 	}
 	return nil
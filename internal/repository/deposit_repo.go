@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type DepositRepository interface {
+	CreateLedgerEntry(entry *model.DepositLedgerEntry) error
+	GetLedgerByBookingID(bookingID uint) ([]model.DepositLedgerEntry, error)
+}
+
+type depositRepository struct {
+	db *gorm.DB
+}
+
+func NewDepositRepository(db *gorm.DB) DepositRepository {
+	return &depositRepository{db: db}
+}
+
+func (r *depositRepository) CreateLedgerEntry(entry *model.DepositLedgerEntry) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *depositRepository) GetLedgerByBookingID(bookingID uint) ([]model.DepositLedgerEntry, error) {
+	var entries []model.DepositLedgerEntry
+	if err := r.db.Where("booking_id = ?", bookingID).Order("created_at, id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
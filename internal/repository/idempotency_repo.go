@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	// CreateRecord stores record unless one already exists for the same
+	// user, key, method and path. It reports whether record was stored.
+	CreateRecord(record *model.IdempotencyRecord) (bool, error)
+	GetRecord(userID uint, key, method, path string) (*model.IdempotencyRecord, error)
+	UpdateRecord(record *model.IdempotencyRecord) error
+	DeleteRecord(recordID uint) error
+	// DeleteRecordsCreatedBefore removes records claimed before cutoff and
+	// returns how many were removed.
+	DeleteRecordsCreatedBefore(cutoff time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) CreateRecord(record *model.IdempotencyRecord) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *idempotencyRepository) GetRecord(userID uint, key, method, path string) (*model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	err := r.db.Where("user_id = ? AND `key` = ? AND method = ? AND path = ?", userID, key, method, path).
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *idempotencyRepository) UpdateRecord(record *model.IdempotencyRecord) error {
+	if err := r.db.Save(record).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteRecord(recordID uint) error {
+	if err := r.db.Delete(&model.IdempotencyRecord{}, recordID).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteRecordsCreatedBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", cutoff).Delete(&model.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
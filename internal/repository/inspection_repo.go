@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type InspectionRepository interface {
+	SaveInspection(inspection *model.BookingInspection) error
+	GetInspection(bookingID uint, kind string) (*model.BookingInspection, error)
+	GetInspectionsByBookingID(bookingID uint) ([]model.BookingInspection, error)
+}
+
+type inspectionRepository struct {
+	db *gorm.DB
+}
+
+func NewInspectionRepository(db *gorm.DB) InspectionRepository {
+	return &inspectionRepository{db: db}
+}
+
+// SaveInspection creates the inspection, or updates it when it has an ID.
+func (r *inspectionRepository) SaveInspection(inspection *model.BookingInspection) error {
+	if err := r.db.Save(inspection).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *inspectionRepository) GetInspection(bookingID uint, kind string) (*model.BookingInspection, error) {
+	var inspection model.BookingInspection
+	if err := r.db.Where("booking_id = ? AND kind = ?", bookingID, kind).First(&inspection).Error; err != nil {
+		return nil, err
+	}
+	return &inspection, nil
+}
+
+func (r *inspectionRepository) GetInspectionsByBookingID(bookingID uint) ([]model.BookingInspection, error) {
+	var inspections []model.BookingInspection
+	if err := r.db.Where("booking_id = ?", bookingID).Order("created_at").Find(&inspections).Error; err != nil {
+		return nil, err
+	}
+	return inspections, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InstallmentRepository interface {
+	GetInstallmentsByBookingID(bookingID uint) ([]model.BookingInstallment, error)
+	GetInstallmentForUpdate(installmentID uint) (*model.BookingInstallment, error)
+	UpdateInstallment(installment *model.BookingInstallment) error
+	// MarkOverdue flags the booking's pending installments that were due
+	// before now.
+	MarkOverdue(bookingID uint, now time.Time) error
+	// MarkAllOverdue flags pending installments that were due before now on
+	// every booking in the given statuses, returning how many changed.
+	MarkAllOverdue(now time.Time, statuses []model.BookingStatus) (int64, error)
+	// CancelUnpaid cancels the booking's installments that are not yet paid.
+	CancelUnpaid(bookingID uint) error
+}
+
+type installmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepository(db *gorm.DB) InstallmentRepository {
+	return &installmentRepository{db: db}
+}
+
+func (r *installmentRepository) GetInstallmentsByBookingID(bookingID uint) ([]model.BookingInstallment, error) {
+	var installments []model.BookingInstallment
+	if err := r.db.Where("booking_id = ?", bookingID).Order("sequence").Find(&installments).Error; err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+func (r *installmentRepository) GetInstallmentForUpdate(installmentID uint) (*model.BookingInstallment, error) {
+	var installment model.BookingInstallment
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&installment, installmentID).Error; err != nil {
+		return nil, err
+	}
+	return &installment, nil
+}
+
+func (r *installmentRepository) UpdateInstallment(installment *model.BookingInstallment) error {
+	if err := r.db.Save(installment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *installmentRepository) MarkOverdue(bookingID uint, now time.Time) error {
+	return r.db.Model(&model.BookingInstallment{}).
+		Where("booking_id = ? AND status = ? AND due_date < ?", bookingID, model.InstallmentPending, now).
+		Update("status", model.InstallmentOverdue).Error
+}
+
+func (r *installmentRepository) MarkAllOverdue(now time.Time, statuses []model.BookingStatus) (int64, error) {
+	bookings := r.db.Model(&model.Booking{}).Select("id").Where("status IN ?", statuses)
+	result := r.db.Model(&model.BookingInstallment{}).
+		Where("status = ? AND due_date < ? AND booking_id IN (?)", model.InstallmentPending, now, bookings).
+		Update("status", model.InstallmentOverdue)
+	return result.RowsAffected, result.Error
+}
+
+func (r *installmentRepository) CancelUnpaid(bookingID uint) error {
+	return r.db.Model(&model.BookingInstallment{}).
+		Where("booking_id = ? AND status IN ?", bookingID, []string{model.InstallmentPending, model.InstallmentOverdue}).
+		Update("status", model.InstallmentCancelled).Error
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnreadCount is the number of unread messages in one booking's thread.
+type UnreadCount struct {
+	BookingID uint  `json:"booking_id"`
+	Unread    int64 `json:"unread"`
+}
+
+type MessageRepository interface {
+	CreateMessage(message *model.BookingMessage) error
+	// GetMessages returns a page of the booking's messages, newest first,
+	// along with the total number of messages in the thread.
+	GetMessages(bookingID uint, limit, offset int) ([]model.BookingMessage, int64, error)
+	GetLatestMessageID(bookingID uint) (uint, error)
+	// MarkRead moves userID's read marker in the booking's thread forward to
+	// messageID. It never moves the marker back.
+	MarkRead(bookingID, userID, messageID uint) error
+	// CountUnread counts messages in the booking's thread sent by someone
+	// other than userID after their read marker.
+	CountUnread(bookingID, userID uint) (int64, error)
+	// GetUnreadCounts lists, for every booking userID rents or owns the car
+	// of, how many messages they have not read. Threads with nothing unread
+	// are left out.
+	GetUnreadCounts(userID uint) ([]UnreadCount, error)
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) CreateMessage(message *model.BookingMessage) error {
+	if err := r.db.Create(message).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *messageRepository) GetMessages(bookingID uint, limit, offset int) ([]model.BookingMessage, int64, error) {
+	query := r.db.Model(&model.BookingMessage{}).Where("booking_id = ?", bookingID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []model.BookingMessage
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
+
+func (r *messageRepository) GetLatestMessageID(bookingID uint) (uint, error) {
+	var id uint
+	err := r.db.Model(&model.BookingMessage{}).
+		Where("booking_id = ?", bookingID).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).Error
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *messageRepository) MarkRead(bookingID, userID, messageID uint) error {
+	marker := model.MessageReadMarker{BookingID: bookingID, UserID: userID, LastReadMessageID: messageID}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_read_message_id": gorm.Expr("GREATEST(last_read_message_id, VALUES(last_read_message_id))"),
+			"updated_at":           gorm.Expr("VALUES(updated_at)"),
+		}),
+	}).Create(&marker).Error
+}
+
+func (r *messageRepository) CountUnread(bookingID, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.BookingMessage{}).
+		Joins("LEFT JOIN message_read_markers ON message_read_markers.booking_id = booking_messages.booking_id AND message_read_markers.user_id = ?", userID).
+		Where("booking_messages.booking_id = ? AND booking_messages.sender_id <> ?", bookingID, userID).
+		Where("booking_messages.id > COALESCE(message_read_markers.last_read_message_id, 0)").
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *messageRepository) GetUnreadCounts(userID uint) ([]UnreadCount, error) {
+	var counts []UnreadCount
+	err := r.db.Model(&model.BookingMessage{}).
+		Select("booking_messages.booking_id AS booking_id, COUNT(*) AS unread").
+		Joins("JOIN bookings ON bookings.id = booking_messages.booking_id").
+		Joins("JOIN cars ON cars.id = bookings.car_id").
+		Joins("LEFT JOIN message_read_markers ON message_read_markers.booking_id = booking_messages.booking_id AND message_read_markers.user_id = ?", userID).
+		Where("(bookings.user_id = ? OR cars.owner_id = ?) AND booking_messages.sender_id <> ?", userID, userID, userID).
+		Where("booking_messages.id > COALESCE(message_read_markers.last_read_message_id, 0)").
+		Group("booking_messages.booking_id").
+		Order("booking_messages.booking_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
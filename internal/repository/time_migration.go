@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// utcMigration names the one-off migration that moves stored times from the
+// server's local wall clock to UTC.
+const utcMigration = "datetimes_to_utc"
+
+const utcMigrationBatch = 500
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+// ConvertLegacyTimesToUTC rewrites every time column of models from legacy
+// wall-clock time to UTC. Before times were stored in UTC, the database
+// connection used loc=Local, so each DATETIME holds the server's local time
+// with no offset; read back with loc=UTC, those rows would be shifted by the
+// server's offset. The migration runs once, in a single transaction, and is
+// recorded in model.SchemaMigration. When legacy is UTC it only records
+// itself.
+func ConvertLegacyTimesToUTC(db *gorm.DB, legacy *time.Location, models ...interface{}) (bool, error) {
+	applied := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.SchemaMigration{Name: utcMigration})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // applied before
+		}
+		applied = true
+		if legacy == time.UTC {
+			return nil
+		}
+		for _, m := range models {
+			if err := convertTableTimes(tx, legacy, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// convertTableTimes converts the time columns of one model's table.
+func convertTableTimes(tx *gorm.DB, legacy *time.Location, m interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(m); err != nil {
+		return err
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return fmt.Errorf("table %s has no primary key", stmt.Schema.Table)
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && (field.FieldType == timeType || field.FieldType == timePtrType) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var last interface{}
+	for {
+		var rows []map[string]interface{}
+		query := tx.Table(stmt.Schema.Table).Select(append([]string{pk.DBName}, columns...)).
+			Order(pk.DBName).Limit(utcMigrationBatch)
+		if last != nil {
+			query = query.Where(clause.Gt{Column: clause.Column{Name: pk.DBName}, Value: last})
+		}
+		if err := query.Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			updates := make(map[string]interface{}, len(columns))
+			for _, column := range columns {
+				t, ok := row[column].(time.Time)
+				if !ok || t.IsZero() {
+					continue
+				}
+				// The stored digits are the legacy local time.
+				updates[column] = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), legacy).UTC()
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			err := tx.Table(stmt.Schema.Table).Where(clause.Eq{Column: clause.Column{Name: pk.DBName}, Value: row[pk.DBName]}).
+				UpdateColumns(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < utcMigrationBatch {
+			return nil
+		}
+		last = rows[len(rows)-1][pk.DBName]
+	}
+}
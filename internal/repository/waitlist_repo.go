@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"rentora-go/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WaitlistRepository interface {
+	CreateEntry(entry *model.WaitlistEntry) error
+	GetEntryForUpdate(entryID uint) (*model.WaitlistEntry, error)
+	GetEntriesByUserID(userID uint) ([]model.WaitlistEntry, error)
+	UpdateEntry(entry *model.WaitlistEntry) error
+	// FindWaitingEntries returns the car's waiting entries intersecting
+	// [start, end), oldest first.
+	FindWaitingEntries(carID uint, start, end time.Time) ([]model.WaitlistEntry, error)
+	// FindActiveHolds returns the car's offered entries intersecting
+	// [start, end) whose hold has not run out at now.
+	FindActiveHolds(carID uint, start, end, now time.Time) ([]model.WaitlistEntry, error)
+	GetExpiredHolds(now time.Time) ([]model.WaitlistEntry, error)
+}
+
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) WaitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (r *waitlistRepository) CreateEntry(entry *model.WaitlistEntry) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *waitlistRepository) GetEntryForUpdate(entryID uint) (*model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, entryID).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *waitlistRepository) GetEntriesByUserID(userID uint) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) UpdateEntry(entry *model.WaitlistEntry) error {
+	if err := r.db.Save(entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *waitlistRepository) FindWaitingEntries(carID uint, start, end time.Time) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	err := r.db.Where("car_id = ? AND status = ? AND start_date < ? AND end_date > ?", carID, model.WaitlistWaiting, end, start).
+		Order("created_at, id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) FindActiveHolds(carID uint, start, end, now time.Time) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	err := r.db.Where("car_id = ? AND status = ? AND hold_expires_at > ? AND start_date < ? AND end_date > ?",
+		carID, model.WaitlistOffered, now, end, start).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) GetExpiredHolds(now time.Time) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	if err := r.db.Where("status = ? AND hold_expires_at <= ?", model.WaitlistOffered, now).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
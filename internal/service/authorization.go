@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrForbidden = errors.New("you are not allowed to access this resource")
+
+// bookingParty is the part a user plays in a booking.
+type bookingParty int
+
+const (
+	partyNone bookingParty = iota
+	partyRenter
+	partyOwner
+)
+
+// transitionParties lists who may move a booking into each status. Statuses
+// without an entry can only be reached by the system (actor ID 0).
+var transitionParties = map[model.BookingStatus][]bookingParty{
+	model.BookingAccepted:  {partyOwner},
+	model.BookingDeclined:  {partyOwner},
+	model.BookingActive:    {partyRenter, partyOwner},
+	model.BookingCompleted: {partyRenter, partyOwner},
+	model.BookingCancelled: {partyRenter, partyOwner},
+}
+
+// partyOf returns how userID relates to booking on car.
+func partyOf(booking *model.Booking, car *model.Car, userID uint) bookingParty {
+	switch userID {
+	case car.OwnerID:
+		return partyOwner
+	case booking.UserID:
+		return partyRenter
+	}
+	return partyNone
+}
+
+// authorizeBookingParty allows the booking's renter and the car's owner.
+func authorizeBookingParty(booking *model.Booking, car *model.Car, userID uint) error {
+	if partyOf(booking, car, userID) == partyNone {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorizeTransition checks that userID may move booking into status to.
+func authorizeTransition(booking *model.Booking, car *model.Car, userID uint, to model.BookingStatus) error {
+	party := partyOf(booking, car, userID)
+	for _, allowed := range transitionParties[to] {
+		if party == allowed {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// authorizeCarOwner allows only the car's owner.
+func authorizeCarOwner(car *model.Car, userID uint) error {
+	if car.OwnerID != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// getOwnedCar loads a car, allowing only its owner.
+func getOwnedCar(carRepo repository.CarRepository, carID, userID uint) (*model.Car, error) {
+	car, err := carRepo.GetCarByID(carID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	if err := authorizeCarOwner(car, userID); err != nil {
+		return nil, err
+	}
+	return car, nil
+}
+
+// getBookingForParty loads a booking and its car, allowing only the renter
+// and the car's owner.
+func getBookingForParty(bookingRepo repository.BookingRepository, carRepo repository.CarRepository, bookingID, userID uint) (*model.Booking, *model.Car, error) {
+	booking, err := bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrBookingNotFound
+		}
+		return nil, nil, err
+	}
+	car, err := carRepo.GetCarByID(booking.CarID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCarNotFound
+		}
+		return nil, nil, err
+	}
+	if err := authorizeBookingParty(booking, car, userID); err != nil {
+		return nil, nil, err
+	}
+	return booking, car, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BookingExpiryWorker periodically expires Pending bookings that the owner
+// has not answered within the configured window.
+type BookingExpiryWorker struct {
+	service  *BookingService
+	window   time.Duration
+	interval time.Duration
+}
+
+func NewBookingExpiryWorker(service *BookingService, window, interval time.Duration) *BookingExpiryWorker {
+	return &BookingExpiryWorker{service: service, window: window, interval: interval}
+}
+
+// Run checks for stale bookings straight away and then once per interval
+// until ctx is cancelled.
+func (w *BookingExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := w.service.ExpireStaleBookings(time.Now().Add(-w.window))
+		if err != nil {
+			log.Printf("Booking expiry failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d pending booking(s)", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+)
+
+// Names of the listing rules a booking can violate.
+const (
+	RuleMinRentalDays    = "min_rental_days"
+	RuleMaxRentalDays    = "max_rental_days"
+	RuleAdvanceNotice    = "advance_notice"
+	RuleTurnaroundBuffer = "turnaround_buffer"
+)
+
+// RuleViolation names a listing rule a booking request breaks.
+type RuleViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// BookingRuleError is returned when a booking request breaks one or more of
+// the car's listing rules. It lists every rule that failed.
+type BookingRuleError struct {
+	Violations []RuleViolation
+}
+
+func (e *BookingRuleError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "booking breaks the listing rules: " + strings.Join(messages, "; ")
+}
+
+// checkBookingRules validates a requested rental of car from start to end,
+// made at now, against the car's listing rules. repo must hold the car's row
+// lock so the turnaround check sees every booking.
+func checkBookingRules(repo repository.BookingRepository, car *model.Car, start, end, now time.Time) error {
+	var violations []RuleViolation
+
+	days := rentalDays(start, end, car.Zone())
+	if car.MinRentalDays > 0 && days < car.MinRentalDays {
+		violations = append(violations, RuleViolation{
+			Rule:    RuleMinRentalDays,
+			Message: fmt.Sprintf("rentals must last at least %d day(s)", car.MinRentalDays),
+		})
+	}
+	if car.MaxRentalDays > 0 && days > car.MaxRentalDays {
+		violations = append(violations, RuleViolation{
+			Rule:    RuleMaxRentalDays,
+			Message: fmt.Sprintf("rentals may last at most %d day(s)", car.MaxRentalDays),
+		})
+	}
+
+	notice := time.Duration(car.AdvanceNoticeHours) * time.Hour
+	if start.Before(now.Add(notice)) {
+		message := "rentals cannot start in the past"
+		if notice > 0 {
+			message = fmt.Sprintf("rentals must be booked at least %d hour(s) in advance", car.AdvanceNoticeHours)
+		}
+		violations = append(violations, RuleViolation{Rule: RuleAdvanceNotice, Message: message})
+	}
+
+	if car.TurnaroundBufferHours > 0 {
+		buffer := time.Duration(car.TurnaroundBufferHours) * time.Hour
+		nearby, err := repo.FindOverlappingBookings(car.ID, start.Add(-buffer), end.Add(buffer), blockingStatuses)
+		if err != nil {
+			return err
+		}
+		if len(nearby) > 0 {
+			violations = append(violations, RuleViolation{
+				Rule:    RuleTurnaroundBuffer,
+				Message: fmt.Sprintf("rentals must leave %d hour(s) between bookings", car.TurnaroundBufferHours),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &BookingRuleError{Violations: violations}
+	}
+	return nil
+}
+
+// validateBookingRules checks that an owner's rule settings make sense.
+func validateBookingRules(car *model.Car) error {
+	if car.MinRentalDays < 0 || car.MaxRentalDays < 0 || car.AdvanceNoticeHours < 0 || car.TurnaroundBufferHours < 0 {
+		return ErrInvalidBookingRules
+	}
+	if car.MaxRentalDays > 0 && car.MaxRentalDays < car.MinRentalDays {
+		return ErrInvalidBookingRules
+	}
+	return nil
+}
@@ -2,65 +2,486 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"time"
+
 	"rentora-go/internal/model"
 	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
 )
 
+var (
+	ErrCarNotFound         = errors.New("car not found")
+	ErrCarUnavailable      = errors.New("car is not available for booking")
+	ErrInvalidBookingDates = errors.New("end date must be after start date")
+	ErrBookingNotFound     = errors.New("booking not found")
+	ErrOwnCarBooking       = errors.New("you cannot book your own car")
+	ErrOwnerMustComplete   = errors.New("only the owner can complete bookings on cars with late return or mileage fees")
+	ErrBookingNotDeletable = errors.New("only cancelled, declined or expired bookings can be deleted; cancel the booking first")
+)
+
+// blockingStatuses are the booking statuses that hold a car's dates.
+var blockingStatuses = []model.BookingStatus{model.BookingPending, model.BookingAccepted, model.BookingActive}
+
+// BookingConflictError is returned when a requested date range overlaps an
+// existing booking that still holds the car.
+type BookingConflictError struct {
+	BookingID uint
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (e *BookingConflictError) Error() string {
+	return fmt.Sprintf("car is already booked from %s to %s",
+		e.StartDate.Format(time.RFC3339), e.EndDate.Format(time.RFC3339))
+}
+
+// DatesBlockedError is returned when a requested date range overlaps a period
+// the owner has blocked.
+type DatesBlockedError struct {
+	BlockID   uint
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+func (e *DatesBlockedError) Error() string {
+	return fmt.Sprintf("car is unavailable from %s to %s",
+		e.StartDate.Format(time.RFC3339), e.EndDate.Format(time.RFC3339))
+}
+
+// InvalidTransitionError is returned when a booking cannot move from its
+// current status to the requested one.
+type InvalidTransitionError struct {
+	From model.BookingStatus
+	To   model.BookingStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("booking cannot move from '%s' to '%s'", e.From, e.To)
+}
+
 type BookingService struct {
-	repo repository.BookingRepository
+	repo     repository.BookingRepository
+	carRepo  repository.CarRepository
+	userRepo repository.UserRepository
+	deposits *DepositService
+	waitlist *WaitlistService
 }
 
-func NewBookingService(repo repository.BookingRepository) *BookingService {
-	return &BookingService{repo: repo}
+func NewBookingService(repo repository.BookingRepository, carRepo repository.CarRepository, userRepo repository.UserRepository, deposits *DepositService, waitlist *WaitlistService) *BookingService {
+	return &BookingService{repo: repo, carRepo: carRepo, userRepo: userRepo, deposits: deposits, waitlist: waitlist}
 }
 
-func (s *BookingService) CreateBooking(booking *model.Booking) error {
-	// Add additional logic to check car availability
-	booking.Status = "Pending" // Default status when booking is created
-	return s.repo.CreateBooking(booking)
+// BookingResult describes how a new booking was priced and confirmed.
+type BookingResult struct {
+	Price        *PriceBreakdown
+	Confirmation string // ConfirmationInstant or ConfirmationRequest
+	// InstantBookIssues explains why a booking on an Instant Book car
+	// still needs the owner's approval.
+	InstantBookIssues []string
 }
 
-func (s *BookingService) AcceptBooking(bookingID uint) error {
-	booking, err := s.repo.GetBookingByID(bookingID)
+// CreateBooking books the car for the requested dates and prices the booking
+// from the car's rates and the chosen extras. Any amounts already set on
+// booking are overwritten. On Instant Book cars, renters meeting the car's
+// requirements are accepted straight away; everyone else waits for the owner
+// in Pending.
+func (s *BookingService) CreateBooking(booking *model.Booking, extras []ExtraChoice) (*BookingResult, error) {
+	booking.StartDate = booking.StartDate.UTC()
+	booking.EndDate = booking.EndDate.UTC()
+	if !booking.EndDate.After(booking.StartDate) {
+		return nil, ErrInvalidBookingDates
+	}
+
+	renter, err := s.userRepo.GetUserByID(booking.UserID)
 	if err != nil {
-		return errors.New("booking not found")
+		return nil, err
 	}
 
-	if booking.Status != "Pending" {
-		return errors.New("booking cannot be accepted because it is not in 'Pending' status")
+	result := &BookingResult{Confirmation: ConfirmationRequest}
+	err = s.repo.Transaction(func(repo repository.BookingRepository) error {
+		// Locking the car row makes concurrent requests for the same car
+		// wait for each other, so both cannot pass the overlap check.
+		car, err := repo.GetCarForUpdate(booking.CarID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if car.OwnerID == booking.UserID {
+			return ErrOwnCarBooking
+		}
+		if !car.Availability {
+			return ErrCarUnavailable
+		}
+
+		conflicts, err := repo.FindOverlappingBookings(booking.CarID, booking.StartDate, booking.EndDate, blockingStatuses)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return &BookingConflictError{
+				BookingID: conflicts[0].ID,
+				StartDate: conflicts[0].StartDate,
+				EndDate:   conflicts[0].EndDate,
+			}
+		}
+
+		blocks, err := repo.FindOverlappingBlocks(booking.CarID, booking.StartDate, booking.EndDate)
+		if err != nil {
+			return err
+		}
+		if len(blocks) > 0 {
+			return &DatesBlockedError{
+				BlockID:   blocks[0].ID,
+				StartDate: blocks[0].StartDate,
+				EndDate:   blocks[0].EndDate,
+			}
+		}
+
+		// Dates offered to someone on the waitlist stay theirs until the
+		// hold runs out.
+		holds, err := s.waitlist.checkHolds(repo, booking, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := checkHourGranularity(car, booking.StartDate, booking.EndDate); err != nil {
+			return err
+		}
+		if err := checkBookingRules(repo, car, booking.StartDate, booking.EndDate, time.Now()); err != nil {
+			return err
+		}
+
+		price, err := CalculatePrice(car, booking.StartDate, booking.EndDate)
+		if err != nil {
+			return err
+		}
+		extraItems, err := extraLineItems(repo.Extras(), car.ID, extras, price.Days)
+		if err != nil {
+			return err
+		}
+		price.addItems(extraItems)
+		booking.TotalAmount = price.Total
+		booking.LineItems = price.LineItems
+		if price.Days > LongTermRentalDays {
+			booking.Installments = installmentSchedule(booking.StartDate, booking.EndDate, booking.TotalAmount, car.Zone())
+		}
+		result.Price = price
+
+		booking.Status = model.BookingPending // Default status when booking is created
+		reason := "booking requested"
+		if car.InstantBook {
+			result.InstantBookIssues = instantBookIssues(renter, booking.EndDate, car.Zone())
+			if len(result.InstantBookIssues) == 0 {
+				booking.Status = model.BookingAccepted
+				result.Confirmation = ConfirmationInstant
+				reason = "confirmed through Instant Book"
+			}
+		}
+
+		if err := repo.CreateBooking(booking); err != nil {
+			return err
+		}
+		if err := s.waitlist.claimHolds(repo, holds, booking); err != nil {
+			return err
+		}
+		if booking.Status == model.BookingAccepted {
+			if err := s.deposits.hold(repo, booking, car, booking.UserID); err != nil {
+				return err
+			}
+			if err := repo.UpdateBooking(booking); err != nil {
+				return err
+			}
+		}
+		return repo.CreateBookingEvent(&model.BookingEvent{
+			BookingID: booking.ID,
+			ActorID:   booking.UserID,
+			ToStatus:  booking.Status,
+			Reason:    reason,
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
+	// Pick up the IDs assigned to the stored line items.
+	result.Price.LineItems = booking.LineItems
+	return result, nil
+}
 
-	booking.Status = "Accepted"
-	return s.repo.UpdateBooking(booking)
+// CarZone returns the time zone in which wall-clock times for bookings of the
+// car are read.
+func (s *BookingService) CarZone(carID uint) (*time.Location, error) {
+	return carZone(s.carRepo, carID)
 }
 
-func (s *BookingService) DeclineBooking(bookingID uint) error {
-	booking, err := s.repo.GetBookingByID(bookingID)
+// AcceptBooking confirms a pending booking and holds the car's security
+// deposit, if it has one.
+func (s *BookingService) AcceptBooking(bookingID, actorID uint, reason string) error {
+	_, err := s.transition(bookingID, model.BookingAccepted, actorID, reason, func(repo repository.BookingRepository, booking *model.Booking, car *model.Car) error {
+		return s.deposits.hold(repo, booking, car, actorID)
+	})
+	return err
+}
+
+func (s *BookingService) DeclineBooking(bookingID, actorID uint, reason string) error {
+	_, err := s.transition(bookingID, model.BookingDeclined, actorID, reason, nil)
+	return err
+}
+
+// PickUpBooking marks an accepted booking as active once the renter has
+// collected the car.
+func (s *BookingService) PickUpBooking(bookingID, actorID uint, reason string) error {
+	_, err := s.transition(bookingID, model.BookingActive, actorID, reason, nil)
+	return err
+}
+
+// ReturnBooking completes an active booking once the car is back and adds
+// late return and mileage overage charges, worked out from the pickup and
+// return inspections, as adjustments on top of the booking's total. The car
+// counts as returned when the return inspection was submitted, or when the
+// booking is completed if there is none. On cars with usage fees only the
+// owner, who takes the car back, may complete the booking.
+func (s *BookingService) ReturnBooking(bookingID, actorID uint, reason string) (*model.Booking, error) {
+	return s.transition(bookingID, model.BookingCompleted, actorID, reason, func(repo repository.BookingRepository, booking *model.Booking, car *model.Car) error {
+		if actorID != car.OwnerID && chargesUsageFees(car) {
+			return ErrOwnerMustComplete
+		}
+
+		pickup, err := getInspection(repo.Inspections(), booking.ID, model.InspectionPickup)
+		if err != nil {
+			return err
+		}
+		ret, err := getInspection(repo.Inspections(), booking.ID, model.InspectionReturn)
+		if err != nil {
+			return err
+		}
+
+		for _, charge := range returnCharges(car, booking, pickup, ret, returnTime(ret, time.Now())) {
+			charge.BookingID = booking.ID
+			if err := repo.CreateBookingAdjustment(&charge); err != nil {
+				return err
+			}
+			booking.Adjustments = append(booking.Adjustments, charge)
+			if charge.Status == model.AdjustmentCharged {
+				booking.AdjustmentTotal += charge.Amount
+			}
+		}
+		booking.AdjustmentTotal = roundMoney(booking.AdjustmentTotal)
+		return nil
+	})
+}
+
+// getInspection returns the booking's inspection of the given kind, or nil
+// when none was submitted.
+func getInspection(inspections repository.InspectionRepository, bookingID uint, kind string) (*model.BookingInspection, error) {
+	inspection, err := inspections.GetInspection(bookingID, kind)
 	if err != nil {
-		return errors.New("booking not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return inspection, nil
+}
+
+// CancelBooking cancels a booking and records the refund owed to the renter
+// under the car's cancellation policy. Bookings the owner never accepted, and
+// cancellations made by the owner, are refunded in full. Long-term bookings
+// refund only what was paid in installments, since the unpaid ones are
+// cancelled with the booking. A held security deposit is released.
+func (s *BookingService) CancelBooking(bookingID, actorID uint, reason string) (*model.Booking, error) {
+	return s.transition(bookingID, model.BookingCancelled, actorID, reason, func(repo repository.BookingRepository, booking *model.Booking, car *model.Car) error {
+		now := time.Now()
+		percent := 100.0
+		if booking.Status != model.BookingPending && actorID != car.OwnerID {
+			percent = car.CancellationPolicy.RefundPercent(booking.StartDate.Sub(now))
+		}
+
+		paid := booking.TotalAmount
+		installments, err := repo.Installments().GetInstallmentsByBookingID(booking.ID)
+		if err != nil {
+			return err
+		}
+		if len(installments) > 0 {
+			paid = paidInstallments(installments)
+		}
+		booking.RefundAmount = roundMoney(paid * percent / 100)
+		booking.CancelledAt = &now
+		return s.deposits.releaseAll(repo, booking, "booking cancelled", actorID)
+	})
+}
 
-	if booking.Status != "Pending" {
-		return errors.New("booking cannot be declined because it is not in 'Pending' status")
+// ExpireStaleBookings moves every booking still Pending since before cutoff
+// to Expired, releasing the car's dates. It returns how many were expired.
+// A booking that fails to expire is logged and skipped so it does not hold
+// up the rest of the batch.
+func (s *BookingService) ExpireStaleBookings(cutoff time.Time) (int, error) {
+	bookings, err := s.repo.GetPendingBookingsCreatedBefore(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, booking := range bookings {
+		_, err := s.transition(booking.ID, model.BookingExpired, 0, "owner did not respond in time", nil)
+		if err != nil {
+			// The owner may have answered since the bookings were listed.
+			var transitionErr *InvalidTransitionError
+			if !errors.As(err, &transitionErr) {
+				log.Printf("Failed to expire booking %d: %v", booking.ID, err)
+			}
+			continue
+		}
+		expired++
 	}
+	return expired, nil
+}
+
+// transitionFunc makes additional changes to a booking inside the same
+// transaction as its status change. It sees the booking's previous status.
+type transitionFunc func(repo repository.BookingRepository, booking *model.Booking, car *model.Car) error
+
+// transition moves a booking to a new status and records the change in the
+// booking's history. Every status change goes through here so the rules in
+// model.BookingStatus and the party checks in transitionParties are applied
+// in one place. An actorID of 0 marks a change made by the system, which is
+// not subject to party checks. apply may be nil. Dates released by a booking
+// that does not go ahead are offered to the car's waitlist.
+func (s *BookingService) transition(bookingID uint, to model.BookingStatus, actorID uint, reason string, apply transitionFunc) (*model.Booking, error) {
+	var booking *model.Booking
+	var offer *model.WaitlistEntry
+	err := s.repo.Transaction(func(repo repository.BookingRepository) error {
+		var err error
+		booking, err = repo.GetBookingForUpdate(bookingID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+
+		car, err := repo.GetCarForUpdate(booking.CarID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if actorID != 0 {
+			if err := authorizeTransition(booking, car, actorID, to); err != nil {
+				return err
+			}
+		}
+
+		from := booking.Status
+		if !from.CanTransitionTo(to) {
+			return &InvalidTransitionError{From: from, To: to}
+		}
+		if apply != nil {
+			if err := apply(repo, booking, car); err != nil {
+				return err
+			}
+		}
 
-	booking.Status = "Declined"
-	return s.repo.UpdateBooking(booking)
+		booking.Status = to
+		if err := repo.UpdateBooking(booking); err != nil {
+			return err
+		}
+		switch to {
+		case model.BookingCancelled, model.BookingDeclined, model.BookingExpired:
+			if err := repo.Installments().CancelUnpaid(booking.ID); err != nil {
+				return err
+			}
+			offer, err = s.waitlist.offerFreedDates(repo, car.ID, booking.StartDate, booking.EndDate, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		return repo.CreateBookingEvent(&model.BookingEvent{
+			BookingID:  booking.ID,
+			ActorID:    actorID,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.waitlist.notifyOffer(offer)
+	return booking, nil
 }
 
+// GetBookingHistory returns the status changes of a booking, oldest first.
+// Only the renter and the car's owner may read it.
+func (s *BookingService) GetBookingHistory(bookingID, userID uint) ([]model.BookingEvent, error) {
+	if _, _, err := getBookingForParty(s.repo, s.carRepo, bookingID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBookingEvents(bookingID)
+}
 
 func (s *BookingService) GetBookingsByUserID(userID uint) ([]model.Booking, error) {
 	return s.repo.GetBookingsByUserID(userID)
 }
 
-func (s *BookingService) GetBookingByID(bookingID uint) (*model.Booking, error) {
-	return s.repo.GetBookingByID(bookingID)
+// GetOwnerBookings lists bookings made on the owner's cars.
+func (s *BookingService) GetOwnerBookings(ownerID uint, filter repository.BookingFilter) ([]model.Booking, int64, error) {
+	return s.repo.GetBookingsByOwnerID(ownerID, filter)
 }
 
-func (s *BookingService) UpdateBooking(booking *model.Booking) error {
-	return s.repo.UpdateBooking(booking)
+// GetBookingByID returns a booking to its renter or the car's owner.
+func (s *BookingService) GetBookingByID(bookingID, userID uint) (*model.Booking, error) {
+	booking, _, err := getBookingForParty(s.repo, s.carRepo, bookingID, userID)
+	return booking, err
 }
 
-func (s *BookingService) DeleteBooking(bookingID uint) error {
-	return s.repo.DeleteBooking(bookingID)
+// UpdateBooking saves changes the renter makes to their booking.
+func (s *BookingService) UpdateBooking(booking *model.Booking, userID uint) error {
+	existing, err := s.repo.GetBookingByID(booking.ID)
+	if err != nil {
+		return ErrBookingNotFound
+	}
+	if existing.UserID != userID {
+		return ErrForbidden
+	}
+
+	// Only the payment method can be edited. Dates would bypass the overlap
+	// check, status only changes through the lifecycle methods and amounts
+	// are only ever calculated by the service.
+	existing.PaymentMethod = booking.PaymentMethod
+	if err := s.repo.UpdateBooking(existing); err != nil {
+		return err
+	}
+	*booking = *existing
+	return nil
+}
+
+// deletableStatuses are the statuses of bookings that never went ahead, so
+// removing them loses no deposit, billing or waitlist state.
+var deletableStatuses = []model.BookingStatus{model.BookingCancelled, model.BookingDeclined, model.BookingExpired}
+
+// DeleteBooking removes a booking on behalf of its renter. Bookings that are
+// still live must be cancelled first so the lifecycle rules apply.
+func (s *BookingService) DeleteBooking(bookingID, userID uint) error {
+	booking, err := s.repo.GetBookingByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookingNotFound
+		}
+		return err
+	}
+	if booking.UserID != userID {
+		return ErrForbidden
+	}
+	for _, status := range deletableStatuses {
+		if booking.Status == status {
+			return s.repo.DeleteBooking(bookingID)
+		}
+	}
+	return ErrBookingNotDeletable
 }
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"rentora-go/internal/ical"
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCalendarToken = errors.New("invalid calendar token")
+	ErrInvalidCalendarFile  = errors.New("invalid iCalendar file")
+	ErrInvalidDateRange     = errors.New("date range must start on or before its end and span at most 366 days")
+)
+
+// Day statuses reported by GetCarAvailability.
+const (
+	DayFree        = "free"
+	DayBooked      = "booked"
+	DayBlocked     = "blocked"     // blocked by the owner or an imported calendar
+	DayHeld        = "held"        // held for a renter offered the dates from the waitlist
+	DayUnavailable = "unavailable" // outside the listing's rules
+)
+
+// maxAvailabilityDays caps the range of a single availability request.
+const maxAvailabilityDays = 366
+
+// DayAvailability is the bookability of a car on one calendar day.
+type DayAvailability struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// calendarStatuses are the booking statuses published in a car's feed.
+var calendarStatuses = []model.BookingStatus{model.BookingAccepted, model.BookingActive, model.BookingCompleted}
+
+// calendarHistory is how far back finished bookings stay in the feed.
+const calendarHistory = 90 * 24 * time.Hour
+
+// CalendarService publishes a car's bookings and blocks as an iCalendar feed
+// that owners can subscribe to from external calendar apps, and imports
+// reservations from other platforms' feeds as blocks.
+type CalendarService struct {
+	bookingRepo  repository.BookingRepository
+	carRepo      repository.CarRepository
+	blockRepo    repository.CarBlockRepository
+	waitlistRepo repository.WaitlistRepository
+	waitlist     *WaitlistService
+}
+
+func NewCalendarService(bookingRepo repository.BookingRepository, carRepo repository.CarRepository, blockRepo repository.CarBlockRepository, waitlistRepo repository.WaitlistRepository, waitlist *WaitlistService) *CalendarService {
+	return &CalendarService{bookingRepo: bookingRepo, carRepo: carRepo, blockRepo: blockRepo, waitlistRepo: waitlistRepo, waitlist: waitlist}
+}
+
+// ImportResult summarizes what an import changed.
+type ImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+}
+
+// ExportCarCalendar builds the feed for a car. The token must match the car's
+// calendar token; an unknown car and a wrong token are indistinguishable.
+func (s *CalendarService) ExportCarCalendar(carID uint, token string) (*ical.Calendar, error) {
+	car, err := s.carRepo.GetCarByID(carID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCalendarToken
+		}
+		return nil, err
+	}
+	if car.CalendarToken == "" || subtle.ConstantTimeCompare([]byte(car.CalendarToken), []byte(token)) != 1 {
+		return nil, ErrInvalidCalendarToken
+	}
+
+	since := time.Now().Add(-calendarHistory)
+	bookings, err := s.bookingRepo.GetCarBookings(car.ID, calendarStatuses, since)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.blockRepo.GetBlocksByCarID(car.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{
+		ProdID: "-//Rentora//Car Calendar//EN",
+		Name:   fmt.Sprintf("%s %s (%d)", car.Make, car.Model, car.Year),
+	}
+	for _, booking := range bookings {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("booking-%d@rentora", booking.ID),
+			Summary:     "Rentora booking",
+			Description: fmt.Sprintf("Booking #%d (%s)", booking.ID, booking.Status),
+			Start:       booking.StartDate,
+			End:         booking.EndDate,
+			Stamp:       booking.UpdatedAt,
+			Status:      "CONFIRMED",
+		})
+	}
+	for _, block := range blocks {
+		// Imported blocks already live in the calendar they came from;
+		// publishing them again would echo them back on the next sync.
+		if block.Source == model.BlockSourceImport {
+			continue
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("block-%d@rentora", block.ID),
+			Summary:     "Unavailable",
+			Description: block.Reason,
+			Start:       block.StartDate,
+			End:         block.EndDate,
+			Stamp:       block.UpdatedAt,
+			Status:      "CONFIRMED",
+		})
+	}
+	return cal, nil
+}
+
+// GetCarAvailability reports, for every day from from to to inclusive,
+// whether the car can be booked. Days are calendar days in the car's time
+// zone; only the dates of from and to are used. A zero from means today in
+// the car's time zone, and a zero to the 30th day from from.
+func (s *CalendarService) GetCarAvailability(carID uint, from, to time.Time) ([]DayAvailability, error) {
+	car, err := s.carRepo.GetCarByID(carID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if from.IsZero() {
+		from = now.In(car.Zone())
+	}
+	from = startOfDay(from, car.Zone())
+	if to.IsZero() {
+		to = from.AddDate(0, 0, 29)
+	}
+	to = startOfDay(to, car.Zone())
+	if to.Before(from) || to.After(from.AddDate(0, 0, maxAvailabilityDays-1)) {
+		return nil, ErrInvalidDateRange
+	}
+
+	buffer := time.Duration(car.TurnaroundBufferHours) * time.Hour
+	bookings, err := s.bookingRepo.GetCarBookings(car.ID, blockingStatuses, from.Add(-buffer))
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.blockRepo.GetBlocksByCarID(car.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := s.waitlistRepo.FindActiveHolds(car.ID, from, to.AddDate(0, 0, 1), now)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []DayAvailability
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		days = append(days, dayAvailability(car, bookings, blocks, holds, day, next, now))
+	}
+	return days, nil
+}
+
+// dayAvailability classifies the day [day, next) as seen at now. A booking
+// outranks a block, which outranks a waitlist hold, which outranks the
+// listing's own rules.
+func dayAvailability(car *model.Car, bookings []model.Booking, blocks []model.CarBlock, holds []model.WaitlistEntry, day, next, now time.Time) DayAvailability {
+	result := DayAvailability{Date: day.Format(time.DateOnly), Status: DayFree}
+	for _, booking := range bookings {
+		if booking.StartDate.Before(next) && booking.EndDate.After(day) {
+			result.Status = DayBooked
+			return result
+		}
+	}
+	for _, block := range blocks {
+		// The block's reason is private to the owner.
+		if block.StartDate.Before(next) && block.EndDate.After(day) {
+			result.Status = DayBlocked
+			return result
+		}
+	}
+	for _, hold := range holds {
+		if hold.StartDate.Before(next) && hold.EndDate.After(day) {
+			result.Status = DayHeld
+			return result
+		}
+	}
+
+	buffer := time.Duration(car.TurnaroundBufferHours) * time.Hour
+	earliestStart := now.Add(time.Duration(car.AdvanceNoticeHours) * time.Hour)
+	switch {
+	case !car.Availability:
+		result.Status = DayUnavailable
+		result.Reason = "listing is paused"
+	case !next.After(now):
+		result.Status = DayUnavailable
+		result.Reason = "date is in the past"
+	case !next.After(earliestStart):
+		result.Status = DayUnavailable
+		result.Reason = "within the required advance notice"
+	case buffer > 0 && withinBuffer(bookings, day, next, buffer):
+		result.Status = DayUnavailable
+		result.Reason = "turnaround time between bookings"
+	}
+	return result
+}
+
+// withinBuffer reports whether [day, next) falls in the turnaround buffer
+// before or after any of the bookings.
+func withinBuffer(bookings []model.Booking, day, next time.Time, buffer time.Duration) bool {
+	for _, booking := range bookings {
+		if booking.StartDate.Add(-buffer).Before(next) && booking.EndDate.Add(buffer).After(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfDay returns midnight in loc on the calendar date of t.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// maxFeedKeyLength caps the stored feed key of imported blocks.
+const maxFeedKeyLength = 255
+
+// ImportCarCalendar turns the events of an external iCalendar file into
+// blocks on the owner's car. Each file belongs to a feed, named by feed or,
+// when that is empty, by the calendar's PRODID, so owners can import from
+// several platforms side by side. Events are matched to earlier imports of
+// the same feed by UID, so importing the same file again changes nothing,
+// moved events are updated and events marked CANCELLED or missing from the
+// file remove their block. Blocks of other feeds are left alone. Dates the
+// import frees are offered to the car's waitlist. The file is applied in a
+// single transaction: either all of it syncs or nothing changes.
+func (s *CalendarService) ImportCarCalendar(carID, userID uint, feed string, r io.Reader) (*ImportResult, error) {
+	car, err := getOwnedCar(s.carRepo, carID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dates and floating times in the file are local to the car.
+	cal, err := ical.ParseCalendarInLocation(r, car.Zone())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendarFile, err)
+	}
+	feed = strings.TrimSpace(feed)
+	if feed == "" {
+		feed = strings.TrimSpace(cal.ProdID)
+	}
+	if len(feed) > maxFeedKeyLength {
+		feed = feed[:maxFeedKeyLength]
+	}
+
+	var result *ImportResult
+	var offers []*model.WaitlistEntry
+	err = s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		// Locking the car keeps two imports of the same car from
+		// interleaving.
+		if _, err := repo.GetCarForUpdate(car.ID); err != nil {
+			return err
+		}
+		var freed []model.CarBlock
+		result, freed, err = syncImportedBlocks(repo.Blocks(), car.ID, feed, cal.Events)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, block := range freed {
+			offer, err := s.waitlist.offerFreedDates(repo, car.ID, block.StartDate, block.EndDate, now)
+			if err != nil {
+				return err
+			}
+			if offer != nil {
+				offers = append(offers, offer)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, offer := range offers {
+		s.waitlist.notifyOffer(offer)
+	}
+	return result, nil
+}
+
+// syncImportedBlocks makes the car's blocks imported from feed match events.
+// Blocks imported before feeds were recorded are adopted by the first feed
+// that lists their UID. It also returns the blocks as they were before being
+// moved or removed, whose dates may now be free.
+func syncImportedBlocks(blockRepo repository.CarBlockRepository, carID uint, feed string, events []ical.Event) (*ImportResult, []model.CarBlock, error) {
+	imported, err := blockRepo.GetImportedBlocks(carID)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocksByUID := make(map[string]*model.CarBlock, len(imported))
+	for i := range imported {
+		block := &imported[i]
+		if block.ExternalFeed != feed && block.ExternalFeed != "" {
+			continue
+		}
+		if other, ok := blocksByUID[block.ExternalUID]; ok && other.ExternalFeed == feed {
+			continue // prefer the feed's own block over a legacy one
+		}
+		blocksByUID[block.ExternalUID] = block
+	}
+
+	result := &ImportResult{}
+	var freed []model.CarBlock
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		existing := blocksByUID[event.UID]
+
+		if event.Status == "CANCELLED" || !event.End.After(event.Start) {
+			if existing != nil {
+				if err := blockRepo.DeleteBlock(existing.ID); err != nil {
+					return nil, nil, err
+				}
+				freed = append(freed, *existing)
+				delete(blocksByUID, event.UID)
+				result.Removed++
+			}
+			continue
+		}
+		seen[event.UID] = true
+
+		reason := event.Summary
+		if reason == "" {
+			reason = "Imported reservation"
+		}
+
+		if existing == nil {
+			block := &model.CarBlock{
+				CarID:        carID,
+				StartDate:    event.Start.UTC(),
+				EndDate:      event.End.UTC(),
+				Reason:       reason,
+				Source:       model.BlockSourceImport,
+				ExternalUID:  event.UID,
+				ExternalFeed: feed,
+			}
+			if err := blockRepo.CreateBlock(block); err != nil {
+				return nil, nil, err
+			}
+			blocksByUID[event.UID] = block
+			result.Created++
+			continue
+		}
+
+		if existing.StartDate.Equal(event.Start) && existing.EndDate.Equal(event.End) &&
+			existing.Reason == reason && existing.ExternalFeed == feed {
+			result.Unchanged++
+			continue
+		}
+		previous := *existing
+		existing.StartDate = event.Start.UTC()
+		existing.EndDate = event.End.UTC()
+		existing.Reason = reason
+		existing.ExternalFeed = feed
+		if err := blockRepo.UpdateBlock(existing); err != nil {
+			return nil, nil, err
+		}
+		if existing.StartDate.After(previous.StartDate) || existing.EndDate.Before(previous.EndDate) {
+			freed = append(freed, previous)
+		}
+		result.Updated++
+	}
+
+	// Events dropped from the feed no longer block the car. Legacy blocks
+	// of unknown origin may belong to another feed and are kept.
+	for uid, block := range blocksByUID {
+		if seen[uid] || block.ExternalFeed != feed {
+			continue
+		}
+		if err := blockRepo.DeleteBlock(block.ID); err != nil {
+			return nil, nil, err
+		}
+		freed = append(freed, *block)
+		result.Removed++
+	}
+	return result, freed, nil
+}
+
+// GetCalendarToken returns the car's feed token to its owner, creating one
+// for cars listed before feeds existed.
+func (s *CalendarService) GetCalendarToken(carID, userID uint) (string, error) {
+	car, err := getOwnedCar(s.carRepo, carID, userID)
+	if err != nil {
+		return "", err
+	}
+	if car.CalendarToken != "" {
+		return car.CalendarToken, nil
+	}
+	return s.setCalendarToken(car)
+}
+
+// RotateCalendarToken replaces the car's feed token, cutting off everyone
+// subscribed with the old one.
+func (s *CalendarService) RotateCalendarToken(carID, userID uint) (string, error) {
+	car, err := getOwnedCar(s.carRepo, carID, userID)
+	if err != nil {
+		return "", err
+	}
+	return s.setCalendarToken(car)
+}
+
+func (s *CalendarService) setCalendarToken(car *model.Car) (string, error) {
+	token, err := newCalendarToken()
+	if err != nil {
+		return "", err
+	}
+	car.CalendarToken = token
+	if err := s.carRepo.UpdateCar(car); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// newCalendarToken returns a random, URL-safe secret.
+func newCalendarToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
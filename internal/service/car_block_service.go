@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBlockNotFound    = errors.New("block not found")
+	ErrImportedBlock    = errors.New("imported blocks are managed by their calendar import")
+	ErrInvalidBlockDate = errors.New("block end date must be after its start date")
+)
+
+// CarBlockService lets owners block date ranges on their cars without taking
+// the whole listing offline.
+type CarBlockService struct {
+	blockRepo   repository.CarBlockRepository
+	bookingRepo repository.BookingRepository
+	carRepo     repository.CarRepository
+	waitlist    *WaitlistService
+}
+
+func NewCarBlockService(blockRepo repository.CarBlockRepository, bookingRepo repository.BookingRepository, carRepo repository.CarRepository, waitlist *WaitlistService) *CarBlockService {
+	return &CarBlockService{blockRepo: blockRepo, bookingRepo: bookingRepo, carRepo: carRepo, waitlist: waitlist}
+}
+
+// ListBlocks returns the car's current and upcoming blocks to its owner.
+func (s *CarBlockService) ListBlocks(carID, userID uint) ([]model.CarBlock, error) {
+	if _, err := getOwnedCar(s.carRepo, carID, userID); err != nil {
+		return nil, err
+	}
+	return s.blockRepo.GetBlocksByCarID(carID, time.Now())
+}
+
+// CarZone returns the time zone in which wall-clock block dates for the car
+// are read.
+func (s *CarBlockService) CarZone(carID uint) (*time.Location, error) {
+	return carZone(s.carRepo, carID)
+}
+
+// CreateBlock blocks a date range on the owner's car. The range may not
+// overlap a booking that already holds the car.
+func (s *CarBlockService) CreateBlock(block *model.CarBlock, userID uint) error {
+	block.StartDate = block.StartDate.UTC()
+	block.EndDate = block.EndDate.UTC()
+	if _, err := getOwnedCar(s.carRepo, block.CarID, userID); err != nil {
+		return err
+	}
+	if !block.EndDate.After(block.StartDate) {
+		return ErrInvalidBlockDate
+	}
+
+	block.ID = 0
+	block.Source = model.BlockSourceOwner
+	block.ExternalUID = ""
+	return s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		if err := checkBlockBookings(repo, block); err != nil {
+			return err
+		}
+		return repo.Blocks().CreateBlock(block)
+	})
+}
+
+// UpdateBlock changes the dates or reason of one of the owner's blocks. Dates
+// the block no longer covers are offered to the car's waitlist.
+func (s *CarBlockService) UpdateBlock(block *model.CarBlock, userID uint) error {
+	existing, err := s.getOwnedBlock(block.CarID, block.ID, userID)
+	if err != nil {
+		return err
+	}
+	if existing.Source == model.BlockSourceImport {
+		return ErrImportedBlock
+	}
+	block.StartDate = block.StartDate.UTC()
+	block.EndDate = block.EndDate.UTC()
+	if !block.EndDate.After(block.StartDate) {
+		return ErrInvalidBlockDate
+	}
+
+	previous := *existing
+	existing.StartDate = block.StartDate
+	existing.EndDate = block.EndDate
+	existing.Reason = block.Reason
+	var offer *model.WaitlistEntry
+	err = s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		if err := checkBlockBookings(repo, existing); err != nil {
+			return err
+		}
+		if err := repo.Blocks().UpdateBlock(existing); err != nil {
+			return err
+		}
+		if existing.StartDate.After(previous.StartDate) || existing.EndDate.Before(previous.EndDate) {
+			offer, err = s.waitlist.offerFreedDates(repo, existing.CarID, previous.StartDate, previous.EndDate, time.Now())
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.waitlist.notifyOffer(offer)
+	*block = *existing
+	return nil
+}
+
+// DeleteBlock frees the dates held by one of the owner's blocks and offers
+// them to the car's waitlist.
+func (s *CarBlockService) DeleteBlock(carID, blockID, userID uint) error {
+	block, err := s.getOwnedBlock(carID, blockID, userID)
+	if err != nil {
+		return err
+	}
+
+	var offer *model.WaitlistEntry
+	err = s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		if _, err := repo.GetCarForUpdate(block.CarID); err != nil {
+			return err
+		}
+		if err := repo.Blocks().DeleteBlock(block.ID); err != nil {
+			return err
+		}
+		offer, err = s.waitlist.offerFreedDates(repo, block.CarID, block.StartDate, block.EndDate, time.Now())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.waitlist.notifyOffer(offer)
+	return nil
+}
+
+// checkBlockBookings locks the car, as CreateBooking does, and fails if a
+// booking holds any of the block's dates.
+func checkBlockBookings(repo repository.BookingRepository, block *model.CarBlock) error {
+	if _, err := repo.GetCarForUpdate(block.CarID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCarNotFound
+		}
+		return err
+	}
+	conflicts, err := repo.FindOverlappingBookings(block.CarID, block.StartDate, block.EndDate, blockingStatuses)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &BookingConflictError{
+			BookingID: conflicts[0].ID,
+			StartDate: conflicts[0].StartDate,
+			EndDate:   conflicts[0].EndDate,
+		}
+	}
+	return nil
+}
+
+func (s *CarBlockService) getOwnedBlock(carID, blockID, userID uint) (*model.CarBlock, error) {
+	if _, err := getOwnedCar(s.carRepo, carID, userID); err != nil {
+		return nil, err
+	}
+	block, err := s.blockRepo.GetBlockByID(blockID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	if block.CarID != carID {
+		return nil, ErrBlockNotFound
+	}
+	return block, nil
+}
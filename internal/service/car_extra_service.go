@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExtraNotFound        = errors.New("extra not found")
+	ErrInvalidExtra         = errors.New("extra needs a name, a pricing type of per_day or flat, and a price and stock that are not negative")
+	ErrInvalidExtraQuantity = errors.New("each extra may be chosen once, with a positive quantity")
+	ErrExtraOutOfStock      = errors.New("not enough of the extra in stock")
+)
+
+// ExtraChoice is an extra a renter adds to a booking.
+type ExtraChoice struct {
+	ExtraID  uint `json:"extra_id"`
+	Quantity int  `json:"quantity"` // defaults to 1
+}
+
+// CarExtraService manages the catalog of extras owners offer with their cars.
+type CarExtraService struct {
+	extraRepo repository.CarExtraRepository
+	carRepo   repository.CarRepository
+}
+
+func NewCarExtraService(extraRepo repository.CarExtraRepository, carRepo repository.CarRepository) *CarExtraService {
+	return &CarExtraService{extraRepo: extraRepo, carRepo: carRepo}
+}
+
+// ListExtras returns the extras offered with a car. The catalog is public so
+// renters can pick extras before booking.
+func (s *CarExtraService) ListExtras(carID uint) ([]model.CarExtra, error) {
+	if _, err := s.carRepo.GetCarByID(carID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	return s.extraRepo.GetExtrasByCarID(carID)
+}
+
+// CreateExtra adds an extra to the owner's car.
+func (s *CarExtraService) CreateExtra(extra *model.CarExtra, userID uint) error {
+	if _, err := getOwnedCar(s.carRepo, extra.CarID, userID); err != nil {
+		return err
+	}
+	if err := validateExtra(extra); err != nil {
+		return err
+	}
+	extra.ID = 0
+	return s.extraRepo.CreateExtra(extra)
+}
+
+// UpdateExtra changes one of the owner's extras. Bookings already made keep
+// the price they were charged.
+func (s *CarExtraService) UpdateExtra(extra *model.CarExtra, userID uint) error {
+	existing, err := s.getOwnedExtra(extra.CarID, extra.ID, userID)
+	if err != nil {
+		return err
+	}
+	if err := validateExtra(extra); err != nil {
+		return err
+	}
+
+	existing.Name = extra.Name
+	existing.Description = extra.Description
+	existing.PricingType = extra.PricingType
+	existing.Price = extra.Price
+	existing.Stock = extra.Stock
+	if err := s.extraRepo.UpdateExtra(existing); err != nil {
+		return err
+	}
+	*extra = *existing
+	return nil
+}
+
+// DeleteExtra removes an extra from the owner's catalog. Line items of
+// bookings that included it are kept.
+func (s *CarExtraService) DeleteExtra(carID, extraID, userID uint) error {
+	if _, err := s.getOwnedExtra(carID, extraID, userID); err != nil {
+		return err
+	}
+	return s.extraRepo.DeleteExtra(extraID)
+}
+
+func (s *CarExtraService) getOwnedExtra(carID, extraID, userID uint) (*model.CarExtra, error) {
+	if _, err := getOwnedCar(s.carRepo, carID, userID); err != nil {
+		return nil, err
+	}
+	extra, err := s.extraRepo.GetExtraByID(extraID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExtraNotFound
+		}
+		return nil, err
+	}
+	if extra.CarID != carID {
+		return nil, ErrExtraNotFound
+	}
+	return extra, nil
+}
+
+func validateExtra(extra *model.CarExtra) error {
+	if extra.PricingType == "" {
+		extra.PricingType = model.ExtraPerDay
+	}
+	if extra.Name == "" || extra.Price < 0 || extra.Stock < 0 ||
+		(extra.PricingType != model.ExtraPerDay && extra.PricingType != model.ExtraFlat) {
+		return ErrInvalidExtra
+	}
+	return nil
+}
+
+// extraLineItems prices the renter's chosen extras for a rental of the given
+// days. Bookings of a car never overlap, so an extra's stock is the most a
+// single booking can take.
+func extraLineItems(repo repository.CarExtraRepository, carID uint, choices []ExtraChoice, days int) ([]model.BookingLineItem, error) {
+	if len(choices) == 0 {
+		return nil, nil
+	}
+	extras, err := repo.GetExtrasByCarID(carID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.CarExtra, len(extras))
+	for _, extra := range extras {
+		byID[extra.ID] = extra
+	}
+
+	var items []model.BookingLineItem
+	chosen := make(map[uint]bool, len(choices))
+	for _, choice := range choices {
+		if choice.Quantity == 0 {
+			choice.Quantity = 1
+		}
+		if choice.Quantity < 0 || chosen[choice.ExtraID] {
+			return nil, ErrInvalidExtraQuantity
+		}
+		chosen[choice.ExtraID] = true
+
+		extra, ok := byID[choice.ExtraID]
+		if !ok {
+			return nil, ErrExtraNotFound
+		}
+		if choice.Quantity > extra.Stock {
+			return nil, fmt.Errorf("%w: %d of %s available", ErrExtraOutOfStock, extra.Stock, extra.Name)
+		}
+
+		extraID := extra.ID
+		item := model.BookingLineItem{
+			Kind:       "extra",
+			Quantity:   choice.Quantity,
+			UnitPrice:  extra.Price,
+			CarExtraID: &extraID,
+		}
+		if extra.PricingType == model.ExtraPerDay {
+			item.Quantity = choice.Quantity * days
+			item.Description = fmt.Sprintf("%s x%d, %d day(s) at %.2f per day", extra.Name, choice.Quantity, days, extra.Price)
+		} else {
+			item.Description = fmt.Sprintf("%s x%d at %.2f each", extra.Name, choice.Quantity, extra.Price)
+		}
+		item.Amount = roundMoney(float64(item.Quantity) * extra.Price)
+		items = append(items, item)
+	}
+	return items, nil
+}
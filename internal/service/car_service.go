@@ -1,9 +1,23 @@
 package service
 
 import (
+	"errors"
+	"time"
+
 	"rentora-go/internal/model"
 	"rentora-go/internal/repository"
 
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCancellationPolicy = errors.New("cancellation policy must be one of flexible, moderate or strict")
+	ErrInvalidBookingRules       = errors.New("booking rules must not be negative and the maximum rental length must not be below the minimum")
+	ErrInvalidUsageFees          = errors.New("mileage allowance, overage rate, grace period and late fee must not be negative")
+	ErrInvalidSecurityDeposit    = errors.New("security deposit must not be negative")
+	ErrInvalidMonthlyDiscount    = errors.New("monthly discount must be between 0 and 100 percent")
+	ErrInvalidTimeZone           = errors.New("time zone must be an IANA zone name such as Europe/Berlin")
+	ErrInvalidHourlyPricing      = errors.New("hourly price and daily cap must not be negative, and a daily cap needs an hourly price")
 )
 
 type CarService struct {
@@ -14,7 +28,18 @@ func NewCarService(repo repository.CarRepository) *CarService {
 	return &CarService{repo: repo}
 }
 
-func (s *CarService) CreateCarListing(car *model.Car) error {
+// CreateCarListing lists a car owned by ownerID.
+func (s *CarService) CreateCarListing(car *model.Car, ownerID uint) error {
+	car.OwnerID = ownerID
+	car.Owner = model.User{} // the owner always comes from the token
+	if err := validateCar(car); err != nil {
+		return err
+	}
+	token, err := newCalendarToken()
+	if err != nil {
+		return err
+	}
+	car.CalendarToken = token
 	return s.repo.CreateCar(car)
 }
 
@@ -22,10 +47,70 @@ func (s *CarService) GetAvailableCars(location string) ([]model.Car, error) {
 	return s.repo.GetAvailableCars(location)
 }
 
-func (s *CarService) UpdateCarListing(car *model.Car) error {
+// UpdateCarListing saves changes to a car on behalf of its owner.
+func (s *CarService) UpdateCarListing(car *model.Car, userID uint) error {
+	existing, err := getOwnedCar(s.repo, car.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	car.OwnerID = existing.OwnerID
+	car.Owner = model.User{}
+	car.CreatedAt = existing.CreatedAt
+	car.CalendarToken = existing.CalendarToken
+	if err := validateCar(car); err != nil {
+		return err
+	}
 	return s.repo.UpdateCar(car)
 }
 
-func (s *CarService) DeleteCarListing(carID uint) error {
+// DeleteCarListing removes a car on behalf of its owner.
+func (s *CarService) DeleteCarListing(carID, userID uint) error {
+	if _, err := getOwnedCar(s.repo, carID, userID); err != nil {
+		return err
+	}
 	return s.repo.DeleteCar(carID)
 }
+
+// validateCar checks the owner-selected settings of a listing, filling in
+// defaults for the ones left empty.
+func validateCar(car *model.Car) error {
+	if car.CancellationPolicy == "" {
+		car.CancellationPolicy = model.CancellationFlexible
+	}
+	if !car.CancellationPolicy.IsValid() {
+		return ErrInvalidCancellationPolicy
+	}
+	if car.MileageAllowancePerDay < 0 || car.OverageRatePerKm < 0 || car.LateReturnGraceMinutes < 0 || car.LateFeePerHour < 0 {
+		return ErrInvalidUsageFees
+	}
+	if car.SecurityDeposit < 0 {
+		return ErrInvalidSecurityDeposit
+	}
+	if car.MonthlyDiscountPercent < 0 || car.MonthlyDiscountPercent > 100 {
+		return ErrInvalidMonthlyDiscount
+	}
+	if car.PricePerHour < 0 || car.DailyCap < 0 || (car.DailyCap > 0 && car.PricePerHour == 0) {
+		return ErrInvalidHourlyPricing
+	}
+	if car.TimeZone == "" {
+		car.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(car.TimeZone); err != nil || car.TimeZone == "Local" {
+		return ErrInvalidTimeZone
+	}
+	return validateBookingRules(car)
+}
+
+// carZone returns the time zone of a car, in which clients' wall-clock
+// booking times are read.
+func carZone(carRepo repository.CarRepository, carID uint) (*time.Location, error) {
+	car, err := carRepo.GetCarByID(carID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	return car.Zone(), nil
+}
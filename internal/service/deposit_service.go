@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoDepositHeld        = errors.New("booking has no security deposit held")
+	ErrDepositNotSettleable = errors.New("deposit can only be settled once the booking is completed")
+	ErrInvalidWithholding   = errors.New("withheld amount must be between zero and the deposit amount")
+	ErrWithholdingReason    = errors.New("a reason is required when withholding part of the deposit")
+)
+
+// DepositService holds a car's security deposit when a booking is accepted
+// and lets the owner release or withhold it after the return. Every movement
+// is appended to the booking's deposit ledger.
+type DepositService struct {
+	depositRepo repository.DepositRepository
+	bookingRepo repository.BookingRepository
+	carRepo     repository.CarRepository
+}
+
+func NewDepositService(depositRepo repository.DepositRepository, bookingRepo repository.BookingRepository, carRepo repository.CarRepository) *DepositService {
+	return &DepositService{depositRepo: depositRepo, bookingRepo: bookingRepo, carRepo: carRepo}
+}
+
+// DepositSummary is a booking's deposit together with its ledger.
+type DepositSummary struct {
+	Amount float64                    `json:"amount"`
+	Status string                     `json:"status"`
+	Ledger []model.DepositLedgerEntry `json:"ledger"`
+}
+
+// GetDeposit returns the booking's deposit to its renter or owner.
+func (s *DepositService) GetDeposit(bookingID, userID uint) (*DepositSummary, error) {
+	booking, _, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := s.depositRepo.GetLedgerByBookingID(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &DepositSummary{Amount: booking.DepositAmount, Status: booking.DepositStatus, Ledger: ledger}, nil
+}
+
+// SettleDeposit releases a completed booking's deposit on behalf of the car's
+// owner, keeping withheld of it for the stated reason. Withholding nothing
+// releases the deposit in full.
+func (s *DepositService) SettleDeposit(bookingID, userID uint, withheld float64, reason string) (*model.Booking, error) {
+	var booking *model.Booking
+	err := s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		var err error
+		booking, err = repo.GetBookingForUpdate(bookingID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+		car, err := repo.GetCarForUpdate(booking.CarID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if err := authorizeCarOwner(car, userID); err != nil {
+			return err
+		}
+
+		if booking.DepositStatus != model.DepositHeld {
+			return ErrNoDepositHeld
+		}
+		if booking.Status != model.BookingCompleted {
+			return ErrDepositNotSettleable
+		}
+		if withheld < 0 || withheld > booking.DepositAmount {
+			return ErrInvalidWithholding
+		}
+		if withheld > 0 && reason == "" {
+			return ErrWithholdingReason
+		}
+
+		if err := s.settle(repo, booking, roundMoney(withheld), reason, userID); err != nil {
+			return err
+		}
+		return repo.UpdateBooking(booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// hold takes the car's security deposit for a booking that was just
+// accepted. It runs inside the caller's transaction; the caller saves the
+// booking.
+func (s *DepositService) hold(repo repository.BookingRepository, booking *model.Booking, car *model.Car, actorID uint) error {
+	if car.SecurityDeposit <= 0 || booking.DepositStatus != "" {
+		return nil
+	}
+
+	booking.DepositAmount = car.SecurityDeposit
+	booking.DepositStatus = model.DepositHeld
+	return repo.Deposits().CreateLedgerEntry(&model.DepositLedgerEntry{
+		BookingID: booking.ID,
+		Type:      model.DepositEntryHold,
+		Amount:    booking.DepositAmount,
+		Reason:    "booking accepted",
+		ActorID:   actorID,
+	})
+}
+
+// releaseAll gives back a held deposit in full, e.g. when an accepted booking
+// is cancelled. It runs inside the caller's transaction; the caller saves the
+// booking.
+func (s *DepositService) releaseAll(repo repository.BookingRepository, booking *model.Booking, reason string, actorID uint) error {
+	if booking.DepositStatus != model.DepositHeld {
+		return nil
+	}
+	return s.settle(repo, booking, 0, reason, actorID)
+}
+
+// settle records the release and any withholding of a held deposit.
+func (s *DepositService) settle(repo repository.BookingRepository, booking *model.Booking, withheld float64, reason string, actorID uint) error {
+	released := roundMoney(booking.DepositAmount - withheld)
+	if released > 0 {
+		entry := &model.DepositLedgerEntry{
+			BookingID: booking.ID,
+			Type:      model.DepositEntryRelease,
+			Amount:    released,
+			Reason:    "deposit released",
+			ActorID:   actorID,
+		}
+		if withheld == 0 && reason != "" {
+			entry.Reason = reason
+		}
+		if err := repo.Deposits().CreateLedgerEntry(entry); err != nil {
+			return err
+		}
+	}
+	if withheld > 0 {
+		err := repo.Deposits().CreateLedgerEntry(&model.DepositLedgerEntry{
+			BookingID: booking.ID,
+			Type:      model.DepositEntryWithhold,
+			Amount:    withheld,
+			Reason:    reason,
+			ActorID:   actorID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case withheld == 0:
+		booking.DepositStatus = model.DepositReleased
+	case released > 0:
+		booking.DepositStatus = model.DepositPartiallyWithheld
+	default:
+		booking.DepositStatus = model.DepositWithheld
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+
+	"rentora-go/internal/pdf"
+)
+
+// Page layout of generated documents, in points.
+const (
+	docMarginX      = 50.0
+	docMarginTop    = 60.0
+	docMarginBottom = 60.0
+	docTextSize     = 10.0
+	docLineHeight   = 14.0
+	docLabelWidth   = 140.0
+)
+
+// Columns of the price table: description on the left, wrapped to
+// docColDescription points, then right-aligned quantity, unit price and
+// amount.
+const (
+	docColDescription = 250.0
+	docColQuantity    = 360.0
+	docColUnit        = 450.0
+	docColAmount      = pdf.PageWidth - docMarginX
+)
+
+// docLayout writes a document top to bottom, starting a new page whenever
+// the current one is full.
+type docLayout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func newDocLayout(title string) *docLayout {
+	l := &docLayout{doc: pdf.New(title)}
+	l.newPage()
+	return l
+}
+
+func (l *docLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = docMarginTop
+}
+
+// reserve makes sure height points fit on the current page.
+func (l *docLayout) reserve(height float64) {
+	if l.y+height > pdf.PageHeight-docMarginBottom {
+		l.newPage()
+	}
+}
+
+func (l *docLayout) title(s string) {
+	l.reserve(30)
+	l.page.Text(docMarginX, l.y+14, 20, true, s)
+	l.y += 34
+}
+
+// section starts a titled section underlined across the page.
+func (l *docLayout) section(s string) {
+	l.reserve(docLineHeight*3 + 10)
+	l.y += 10
+	l.page.Text(docMarginX, l.y, 12, true, s)
+	l.y += 5
+	l.page.Line(docMarginX, l.y, pdf.PageWidth-docMarginX, l.y)
+	l.y += docLineHeight
+}
+
+func (l *docLayout) text(s string) {
+	l.reserve(docLineHeight)
+	l.page.Text(docMarginX, l.y, docTextSize, false, s)
+	l.y += docLineHeight
+}
+
+// paragraph writes s wrapped to the page width.
+func (l *docLayout) paragraph(s string) {
+	for _, line := range wrapText(s, pdf.PageWidth-2*docMarginX, docTextSize) {
+		l.text(line)
+	}
+}
+
+// field writes a bold label followed by its value. Empty values are skipped.
+func (l *docLayout) field(label, value string) {
+	if value == "" {
+		return
+	}
+	l.reserve(docLineHeight)
+	l.page.Text(docMarginX, l.y, docTextSize, true, label)
+	l.page.Text(docMarginX+docLabelWidth, l.y, docTextSize, false, value)
+	l.y += docLineHeight
+}
+
+// row writes one row of the price table. Long descriptions wrap onto
+// further lines below the figures.
+func (l *docLayout) row(description, quantity, unit, amount string, bold bool) {
+	lines := wrapText(description, docColDescription, docTextSize)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	l.reserve(docLineHeight * float64(len(lines)))
+	l.page.TextRight(docColQuantity, l.y, docTextSize, bold, quantity)
+	l.page.TextRight(docColUnit, l.y, docTextSize, bold, unit)
+	l.page.TextRight(docColAmount, l.y, docTextSize, bold, amount)
+	for _, line := range lines {
+		l.page.Text(docMarginX, l.y, docTextSize, bold, line)
+		l.y += docLineHeight
+	}
+}
+
+// total writes a right-aligned label and amount below the price table.
+func (l *docLayout) total(label, amount string, bold bool) {
+	l.reserve(docLineHeight)
+	l.page.TextRight(docColUnit, l.y, docTextSize, bold, label)
+	l.page.TextRight(docColAmount, l.y, docTextSize, bold, amount)
+	l.y += docLineHeight
+}
+
+func (l *docLayout) rule() {
+	l.reserve(docLineHeight)
+	l.y -= 4
+	l.page.Line(docMarginX, l.y, pdf.PageWidth-docMarginX, l.y)
+	l.y += docLineHeight - 4
+}
+
+// signatures writes a signature line for each party side by side, with the
+// party's role and name underneath and, if given, the date they signed.
+func (l *docLayout) signatures(parties ...docSignature) {
+	l.reserve(80)
+	l.y += 40
+	width := (pdf.PageWidth - 2*docMarginX) / float64(len(parties))
+	for i, party := range parties {
+		x := docMarginX + float64(i)*width
+		l.page.Line(x, l.y, x+width-30, l.y)
+		l.page.Text(x, l.y+docLineHeight, docTextSize, true, party.Role)
+		l.page.Text(x, l.y+2*docLineHeight, docTextSize, false, party.Name)
+		date := "Date: ____________"
+		if party.SignedOn != "" {
+			date = "Date: " + party.SignedOn
+		}
+		l.page.Text(x, l.y+3*docLineHeight, docTextSize, false, date)
+	}
+	l.y += 4 * docLineHeight
+}
+
+// wrapText breaks s into lines at most width points wide. Words too long
+// for a line of their own are split between characters.
+func wrapText(s string, width, size float64) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if pdf.TextWidth(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = ""
+		for _, r := range word {
+			if line != "" && pdf.TextWidth(line+string(r), size) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (l *docLayout) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := l.doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// docSignature is one party's signature block.
+type docSignature struct {
+	Role     string
+	Name     string
+	SignedOn string
+}
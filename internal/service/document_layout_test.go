@@ -0,0 +1,39 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"rentora-go/internal/pdf"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		lines int
+	}{
+		{"empty", "", 100, 0},
+		{"fits on one line", "Rental, 3 days", 250, 1},
+		{"wraps between words", "Child seat for toddlers aged one to four, rear facing where required", 120, 3},
+		{"splits a word longer than the line", strings.Repeat("x", 80), 100, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := wrapText(tt.text, tt.width, docTextSize)
+			if len(lines) != tt.lines {
+				t.Errorf("got %d lines %q, want %d", len(lines), lines, tt.lines)
+			}
+			for _, line := range lines {
+				if w := pdf.TextWidth(line, docTextSize); w > tt.width {
+					t.Errorf("line %q is %.1f points wide, more than %.1f", line, w, tt.width)
+				}
+			}
+			if got := strings.Join(strings.Fields(strings.Join(lines, " ")), ""); got != strings.Join(strings.Fields(tt.text), "") {
+				t.Errorf("wrapping lost text: %q", lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrDocumentNotAvailable = errors.New("document is not available for the booking's current status")
+
+// Bookings that never went ahead get neither document. Cancelled bookings
+// still get an invoice showing what was refunded.
+var (
+	invoiceStatuses   = []model.BookingStatus{model.BookingAccepted, model.BookingActive, model.BookingCompleted, model.BookingCancelled}
+	agreementStatuses = []model.BookingStatus{model.BookingAccepted, model.BookingActive, model.BookingCompleted}
+)
+
+const docTimeFormat = "2 Jan 2006 15:04 MST"
+
+// DocumentService renders printable PDF invoices and rental agreements for
+// bookings. Prices include tax at taxRate percent.
+type DocumentService struct {
+	bookingRepo    repository.BookingRepository
+	carRepo        repository.CarRepository
+	userRepo       repository.UserRepository
+	inspectionRepo repository.InspectionRepository
+	taxRate        float64
+}
+
+func NewDocumentService(bookingRepo repository.BookingRepository, carRepo repository.CarRepository, userRepo repository.UserRepository, inspectionRepo repository.InspectionRepository, taxRate float64) *DocumentService {
+	return &DocumentService{
+		bookingRepo:    bookingRepo,
+		carRepo:        carRepo,
+		userRepo:       userRepo,
+		inspectionRepo: inspectionRepo,
+		taxRate:        taxRate,
+	}
+}
+
+// bookingDocument is everything a document about a booking shows.
+type bookingDocument struct {
+	booking *model.Booking
+	car     *model.Car
+	renter  *model.User
+	owner   *model.User
+}
+
+func (s *DocumentService) load(bookingID, userID uint, statuses []model.BookingStatus) (*bookingDocument, error) {
+	booking, car, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsStatus(statuses, booking.Status) {
+		return nil, ErrDocumentNotAvailable
+	}
+	renter, err := s.userRepo.GetUserByID(booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.userRepo.GetUserByID(car.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	return &bookingDocument{booking: booking, car: car, renter: renter, owner: owner}, nil
+}
+
+// Invoice renders the booking's invoice for its renter or owner.
+func (s *DocumentService) Invoice(bookingID, userID uint) ([]byte, error) {
+	d, err := s.load(bookingID, userID, invoiceStatuses)
+	if err != nil {
+		return nil, err
+	}
+	booking := d.booking
+
+	l := newDocLayout(fmt.Sprintf("Invoice INV-%06d", booking.ID))
+	l.title("Invoice")
+	l.field("Invoice number", fmt.Sprintf("INV-%06d", booking.ID))
+	issued, err := s.issueDate(booking)
+	if err != nil {
+		return nil, err
+	}
+	l.field("Issued", issued.UTC().Format(docTimeFormat))
+	l.field("Booking status", string(booking.Status))
+	l.field("Payment method", booking.PaymentMethod)
+
+	l.section("Billed to")
+	l.text(fullName(d.renter))
+	l.text(d.renter.Email)
+	for _, line := range addressLines(d.renter) {
+		l.text(line)
+	}
+
+	l.section("Rental")
+	l.field("Vehicle", carTitle(d.car))
+	l.field("Location", d.car.Location)
+	l.field("Owner", fullName(d.owner))
+	l.field("Pickup", booking.StartDate.In(d.car.Zone()).Format(docTimeFormat))
+	l.field("Return", booking.EndDate.In(d.car.Zone()).Format(docTimeFormat))
+
+	l.section("Charges")
+	l.row("Description", "Qty", "Unit price", "Amount", true)
+	l.rule()
+	for _, item := range booking.LineItems {
+		l.row(item.Description, fmt.Sprint(item.Quantity), money(item.UnitPrice), money(item.Amount), false)
+	}
+	var disputed []model.BookingAdjustment
+	for _, adjustment := range booking.Adjustments {
+		if adjustment.Status == model.AdjustmentDisputed {
+			disputed = append(disputed, adjustment)
+			continue
+		}
+		l.row(adjustment.Description, fmt.Sprint(adjustment.Quantity), money(adjustment.UnitPrice), money(adjustment.Amount), false)
+	}
+	l.rule()
+
+	total := roundMoney(booking.TotalAmount + booking.AdjustmentTotal)
+	tax := roundMoney(total - total/(1+s.taxRate/100))
+	l.total("Net", money(total-tax), false)
+	l.total(fmt.Sprintf("Tax (%g%%)", s.taxRate), money(tax), false)
+	l.total("Total", money(total), true)
+	if booking.Status == model.BookingCancelled {
+		// Long-term bookings were only charged the installments paid
+		// before the cancellation; the rest were cancelled unpaid.
+		paid := total
+		installments, err := s.bookingRepo.Installments().GetInstallmentsByBookingID(booking.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(installments) > 0 {
+			paid = roundMoney(paidInstallments(installments))
+			l.total("Paid in installments", money(paid), false)
+		}
+		l.total("Refunded", money(-booking.RefundAmount), false)
+		l.total("Amount charged", money(paid-booking.RefundAmount), true)
+	}
+
+	if len(disputed) > 0 {
+		l.section("Disputed charges")
+		l.paragraph("These charges are based on inspection readings that were not confirmed by both parties. They are not included in the total above.")
+		l.row("Description", "Qty", "Unit price", "Amount", true)
+		l.rule()
+		for _, adjustment := range disputed {
+			l.row(adjustment.Description, fmt.Sprint(adjustment.Quantity), money(adjustment.UnitPrice), money(adjustment.Amount), false)
+		}
+	}
+
+	if booking.DepositAmount > 0 {
+		l.section("Security deposit")
+		l.paragraph(fmt.Sprintf("A security deposit of %s is held separately from the charges above and is not taxed. Current status: %s.",
+			money(booking.DepositAmount), strings.ReplaceAll(booking.DepositStatus, "_", " ")))
+	}
+	return l.bytes()
+}
+
+// issueDate is the fixed date an invoice is issued on, so every download of
+// the same invoice shows the same date: when the booking was accepted, or for
+// bookings cancelled before acceptance, when they were cancelled.
+func (s *DocumentService) issueDate(booking *model.Booking) (time.Time, error) {
+	events, err := s.bookingRepo.GetBookingEvents(booking.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, event := range events {
+		if event.ToStatus == model.BookingAccepted {
+			return event.CreatedAt, nil
+		}
+	}
+	if booking.CancelledAt != nil {
+		return *booking.CancelledAt, nil
+	}
+	return booking.CreatedAt, nil
+}
+
+// Agreement renders the booking's rental agreement for its renter or owner,
+// with signature blocks for both. Signature dates are filled in from the
+// pickup inspection once each party has signed it.
+func (s *DocumentService) Agreement(bookingID, userID uint) ([]byte, error) {
+	d, err := s.load(bookingID, userID, agreementStatuses)
+	if err != nil {
+		return nil, err
+	}
+	booking, car := d.booking, d.car
+
+	l := newDocLayout(fmt.Sprintf("Rental agreement RA-%06d", booking.ID))
+	l.title("Rental Agreement")
+	l.field("Agreement number", fmt.Sprintf("RA-%06d", booking.ID))
+	l.field("Booked on", booking.CreatedAt.In(car.Zone()).Format(docTimeFormat))
+
+	l.section("Owner")
+	l.field("Name", fullName(d.owner))
+	l.field("Email", d.owner.Email)
+	l.field("Phone", d.owner.PhoneNumber)
+
+	l.section("Renter")
+	l.field("Name", fullName(d.renter))
+	l.field("Email", d.renter.Email)
+	l.field("Phone", d.renter.PhoneNumber)
+	l.field("Address", strings.Join(addressLines(d.renter), ", "))
+	l.field("Driver's licence", strings.TrimSpace(d.renter.DriversLicenseNumber+" "+d.renter.DriversLicenseState))
+	if !d.renter.DriversLicenseExpiration.IsZero() {
+		l.field("Licence expires", d.renter.DriversLicenseExpiration.Format(time.DateOnly))
+	}
+
+	l.section("Vehicle and rental period")
+	l.field("Vehicle", carTitle(car))
+	l.field("Location", car.Location)
+	l.field("Pickup", booking.StartDate.In(car.Zone()).Format(docTimeFormat))
+	l.field("Return", booking.EndDate.In(car.Zone()).Format(docTimeFormat))
+	l.field("Rental days", fmt.Sprint(rentalDays(booking.StartDate, booking.EndDate, car.Zone())))
+
+	l.section("Charges")
+	l.field("Rental total", money(booking.TotalAmount))
+	if booking.DepositAmount > 0 {
+		l.field("Security deposit", money(booking.DepositAmount))
+	}
+	l.field("Payment method", booking.PaymentMethod)
+
+	l.section("Terms")
+	l.text(fmt.Sprintf("Cancellation (%s policy):", car.CancellationPolicy))
+	for _, term := range car.CancellationPolicy.Terms() {
+		l.text("- " + term)
+	}
+	if car.MileageAllowancePerDay > 0 {
+		l.paragraph(fmt.Sprintf("Mileage: %d km per rental day are included; each further km is charged at %s.",
+			car.MileageAllowancePerDay, money(car.OverageRatePerKm)))
+	}
+	if car.LateFeePerHour > 0 {
+		l.paragraph(fmt.Sprintf("Late return: returns more than %d minutes after the agreed time are charged %s per started hour.",
+			car.LateReturnGraceMinutes, money(car.LateFeePerHour)))
+	}
+	if booking.DepositAmount > 0 {
+		l.paragraph("Security deposit: the deposit is held from acceptance until after the return. The owner may withhold part or all of it for damage or unpaid charges, stating the reason.")
+	}
+	l.paragraph("The renter returns the vehicle at the agreed time and place in the condition recorded at pickup, apart from normal wear. Both parties confirm the vehicle's condition through the pickup and return inspections.")
+
+	var renterSigned, ownerSigned string
+	pickup, err := s.inspectionRepo.GetInspection(booking.ID, model.InspectionPickup)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if pickup != nil {
+		l.section("Pickup inspection")
+		l.field("Odometer", fmt.Sprintf("%d km", pickup.Odometer))
+		l.field("Fuel / charge", fmt.Sprintf("%d%%", pickup.FuelLevel))
+		l.field("Damage noted", fmt.Sprint(len(pickup.DamageChecklist), " item(s)"))
+		if pickup.RenterSignedAt != nil {
+			renterSigned = pickup.RenterSignedAt.In(car.Zone()).Format(time.DateOnly)
+		}
+		if pickup.OwnerSignedAt != nil {
+			ownerSigned = pickup.OwnerSignedAt.In(car.Zone()).Format(time.DateOnly)
+		}
+	}
+
+	l.section("Signatures")
+	l.signatures(
+		docSignature{Role: "Owner", Name: fullName(d.owner), SignedOn: ownerSigned},
+		docSignature{Role: "Renter", Name: fullName(d.renter), SignedOn: renterSigned},
+	)
+	return l.bytes()
+}
+
+func fullName(u *model.User) string {
+	return strings.Join(strings.Fields(u.FirstName+" "+u.OtherName+" "+u.LastName), " ")
+}
+
+func addressLines(u *model.User) []string {
+	var lines []string
+	if u.Address != "" {
+		lines = append(lines, u.Address)
+	}
+	if city := strings.Join(strings.Fields(u.PostalCode+" "+u.City), " "); city != "" {
+		lines = append(lines, city)
+	}
+	if region := strings.Trim(u.Region+", "+u.Country, ", "); region != "" {
+		lines = append(lines, region)
+	}
+	return lines
+}
+
+func carTitle(car *model.Car) string {
+	return fmt.Sprintf("%d %s %s", car.Year, car.Make, car.Model)
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("%.2f", roundMoney(amount))
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"rentora-go/internal/model"
+)
+
+// chargesUsageFees reports whether car charges for late returns or mileage,
+// in which case only its owner may complete bookings.
+func chargesUsageFees(car *model.Car) bool {
+	return car.LateFeePerHour > 0 || (car.MileageAllowancePerDay > 0 && car.OverageRatePerKm > 0)
+}
+
+// returnTime is when the car counts as returned: when the return inspection
+// was first signed, by whichever party submitted it, so completing the
+// booking later does not add late hours. Without a return inspection it is
+// completedAt.
+func returnTime(ret *model.BookingInspection, completedAt time.Time) time.Time {
+	if ret == nil {
+		return completedAt
+	}
+	var signed *time.Time
+	for _, at := range []*time.Time{ret.RenterSignedAt, ret.OwnerSignedAt} {
+		if at != nil && (signed == nil || at.Before(*signed)) {
+			signed = at
+		}
+	}
+	if signed == nil {
+		return completedAt
+	}
+	return *signed
+}
+
+// returnCharges works out the extra charges owed for a finished rental of
+// car. Mileage overage needs both inspections; unless both are countersigned,
+// so neither party alone chose the odometer readings, it is added as a
+// disputed charge that is not billed. A return later than the car's grace
+// period is charged per started hour from the booked end.
+func returnCharges(car *model.Car, booking *model.Booking, pickup, ret *model.BookingInspection, returnedAt time.Time) []model.BookingAdjustment {
+	var charges []model.BookingAdjustment
+
+	if car.MileageAllowancePerDay > 0 && car.OverageRatePerKm > 0 && pickup != nil && ret != nil {
+		allowance := car.MileageAllowancePerDay * rentalDays(booking.StartDate, booking.EndDate, car.Zone())
+		driven := ret.Odometer - pickup.Odometer
+		if over := driven - allowance; over > 0 {
+			status := model.AdjustmentCharged
+			if !pickup.IsCountersigned() || !ret.IsCountersigned() {
+				status = model.AdjustmentDisputed
+			}
+			charges = append(charges, model.BookingAdjustment{
+				Kind:        "mileage_overage",
+				Description: fmt.Sprintf("%d km over the %d km allowance", over, allowance),
+				Quantity:    over,
+				UnitPrice:   car.OverageRatePerKm,
+				Amount:      roundMoney(float64(over) * car.OverageRatePerKm),
+				Status:      status,
+			})
+		}
+	}
+
+	grace := time.Duration(car.LateReturnGraceMinutes) * time.Minute
+	if car.LateFeePerHour > 0 && returnedAt.After(booking.EndDate.Add(grace)) {
+		hours := int(math.Ceil(returnedAt.Sub(booking.EndDate).Hours()))
+		charges = append(charges, model.BookingAdjustment{
+			Kind:        "late_return",
+			Description: fmt.Sprintf("Returned %d hour(s) late", hours),
+			Quantity:    hours,
+			UnitPrice:   car.LateFeePerHour,
+			Amount:      roundMoney(float64(hours) * car.LateFeePerHour),
+			Status:      model.AdjustmentCharged,
+		})
+	}
+
+	return charges
+}
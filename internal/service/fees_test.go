@@ -0,0 +1,181 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"rentora-go/internal/model"
+)
+
+func TestReturnCharges(t *testing.T) {
+	start := time.Date(2026, time.June, 1, 10, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3)
+	booking := &model.Booking{StartDate: start, EndDate: end}
+	signed := time.Date(2026, time.May, 31, 0, 0, 0, 0, time.UTC)
+
+	inspection := func(odometer int, countersigned bool) *model.BookingInspection {
+		i := &model.BookingInspection{Odometer: odometer, RenterSignedAt: &signed}
+		if countersigned {
+			i.OwnerSignedAt = &signed
+		}
+		return i
+	}
+	feeCar := &model.Car{
+		MileageAllowancePerDay: 100,
+		OverageRatePerKm:       0.25,
+		LateReturnGraceMinutes: 30,
+		LateFeePerHour:         15,
+	}
+
+	tests := []struct {
+		name       string
+		car        *model.Car
+		pickup     *model.BookingInspection
+		ret        *model.BookingInspection
+		returnedAt time.Time
+		want       map[string]float64 // adjustment amount by kind
+		disputed   string             // kind of the one disputed adjustment, if any
+	}{
+		{
+			name:       "no fees configured",
+			car:        &model.Car{},
+			pickup:     inspection(1000, true),
+			ret:        inspection(2000, true),
+			returnedAt: end.Add(5 * time.Hour),
+			want:       map[string]float64{},
+		},
+		{
+			name:       "within allowance and on time",
+			car:        feeCar,
+			pickup:     inspection(1000, true),
+			ret:        inspection(1300, true),
+			returnedAt: end,
+			want:       map[string]float64{},
+		},
+		{
+			name:       "mileage over the allowance",
+			car:        feeCar,
+			pickup:     inspection(1000, true),
+			ret:        inspection(1450, true),
+			returnedAt: end,
+			want:       map[string]float64{"mileage_overage": 37.5},
+		},
+		{
+			name:       "mileage disputed without countersigned return",
+			car:        feeCar,
+			pickup:     inspection(1000, true),
+			ret:        inspection(1450, false),
+			returnedAt: end,
+			want:       map[string]float64{"mileage_overage": 37.5},
+			disputed:   "mileage_overage",
+		},
+		{
+			name:       "mileage disputed without countersigned pickup",
+			car:        feeCar,
+			pickup:     inspection(1000, false),
+			ret:        inspection(1450, true),
+			returnedAt: end.Add(90 * time.Minute),
+			want:       map[string]float64{"mileage_overage": 37.5, "late_return": 30},
+			disputed:   "mileage_overage",
+		},
+		{
+			name:       "mileage skipped without inspections",
+			car:        feeCar,
+			returnedAt: end,
+			want:       map[string]float64{},
+		},
+		{
+			name:       "late within the grace period",
+			car:        feeCar,
+			returnedAt: end.Add(30 * time.Minute),
+			want:       map[string]float64{},
+		},
+		{
+			name:       "late charged per started hour from the booked end",
+			car:        feeCar,
+			returnedAt: end.Add(2*time.Hour + 10*time.Minute),
+			want:       map[string]float64{"late_return": 45},
+		},
+		{
+			name:       "late and over the allowance",
+			car:        feeCar,
+			pickup:     inspection(0, true),
+			ret:        inspection(301, true),
+			returnedAt: end.Add(31 * time.Minute),
+			want:       map[string]float64{"mileage_overage": 0.25, "late_return": 15},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges := returnCharges(tt.car, booking, tt.pickup, tt.ret, tt.returnedAt)
+			if len(charges) != len(tt.want) {
+				t.Fatalf("got %d charges %+v, want %d", len(charges), charges, len(tt.want))
+			}
+			for _, charge := range charges {
+				want, ok := tt.want[charge.Kind]
+				if !ok {
+					t.Errorf("unexpected %s charge of %.2f", charge.Kind, charge.Amount)
+					continue
+				}
+				if charge.Amount != want {
+					t.Errorf("%s charge %.2f, want %.2f", charge.Kind, charge.Amount, want)
+				}
+				wantStatus := model.AdjustmentCharged
+				if charge.Kind == tt.disputed {
+					wantStatus = model.AdjustmentDisputed
+				}
+				if charge.Status != wantStatus {
+					t.Errorf("%s charge status %q, want %q", charge.Kind, charge.Status, wantStatus)
+				}
+				if got := roundMoney(float64(charge.Quantity) * charge.UnitPrice); got != charge.Amount {
+					t.Errorf("%s: %d x %.2f does not make %.2f", charge.Kind, charge.Quantity, charge.UnitPrice, charge.Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestReturnTime(t *testing.T) {
+	completed := time.Date(2026, time.June, 6, 18, 0, 0, 0, time.UTC)
+	submitted := time.Date(2026, time.June, 4, 11, 0, 0, 0, time.UTC)
+	countersigned := time.Date(2026, time.June, 5, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		ret  *model.BookingInspection
+		want time.Time
+	}{
+		{"no return inspection", nil, completed},
+		{"unsigned return inspection", &model.BookingInspection{}, completed},
+		{"submitted by the renter", &model.BookingInspection{RenterSignedAt: &submitted}, submitted},
+		{"submitted by the owner", &model.BookingInspection{OwnerSignedAt: &submitted}, submitted},
+		{"countersigned later", &model.BookingInspection{RenterSignedAt: &submitted, OwnerSignedAt: &countersigned}, submitted},
+		{"countersigned by the renter", &model.BookingInspection{RenterSignedAt: &countersigned, OwnerSignedAt: &submitted}, submitted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := returnTime(tt.ret, completed); !got.Equal(tt.want) {
+				t.Errorf("returnTime = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChargesUsageFees(t *testing.T) {
+	tests := []struct {
+		car  model.Car
+		want bool
+	}{
+		{model.Car{}, false},
+		{model.Car{LateFeePerHour: 10}, true},
+		{model.Car{MileageAllowancePerDay: 100}, false},
+		{model.Car{MileageAllowancePerDay: 100, OverageRatePerKm: 0.3}, true},
+	}
+	for _, tt := range tests {
+		if got := chargesUsageFees(&tt.car); got != tt.want {
+			t.Errorf("chargesUsageFees(%+v) = %v, want %v", tt.car, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"rentora-go/internal/repository"
+)
+
+// IdempotencyPurgeWorker periodically deletes stored Idempotency-Key
+// responses once they are older than ttl and can no longer be replayed.
+type IdempotencyPurgeWorker struct {
+	repo     repository.IdempotencyRepository
+	ttl      time.Duration
+	interval time.Duration
+}
+
+func NewIdempotencyPurgeWorker(repo repository.IdempotencyRepository, ttl, interval time.Duration) *IdempotencyPurgeWorker {
+	return &IdempotencyPurgeWorker{repo: repo, ttl: ttl, interval: interval}
+}
+
+// Run purges expired keys straight away and then once per interval until
+// ctx is cancelled.
+func (w *IdempotencyPurgeWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := w.repo.DeleteRecordsCreatedBefore(time.Now().Add(-w.ttl))
+		if err != nil {
+			log.Printf("Idempotency key purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d expired Idempotency-Key record(s)", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
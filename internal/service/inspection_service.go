@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidInspectionKind = errors.New("inspection kind must be pickup or return")
+	ErrInvalidInspection     = errors.New("odometer must not be negative and fuel level must be between 0 and 100")
+	ErrInspectionNotFound    = errors.New("inspection not found")
+	ErrInspectionSigned      = errors.New("inspection has already been signed by both parties")
+	ErrInspectionNotAllowed  = errors.New("inspection cannot be recorded in the booking's current status")
+	ErrAlreadySigned         = errors.New("you have already signed this inspection")
+)
+
+// inspectionStatuses lists the booking statuses in which each kind of
+// inspection may be submitted or signed. Once the booking moves on, the
+// inspection is frozen: the pickup inspection when the car is collected and
+// the return inspection when the booking completes.
+var inspectionStatuses = map[string][]model.BookingStatus{
+	model.InspectionPickup: {model.BookingAccepted},
+	model.InspectionReturn: {model.BookingActive},
+}
+
+// InspectionService records the condition of a car at pickup and return so
+// both parties agree on odometer, fuel and damage.
+type InspectionService struct {
+	inspectionRepo repository.InspectionRepository
+	bookingRepo    repository.BookingRepository
+	carRepo        repository.CarRepository
+}
+
+func NewInspectionService(inspectionRepo repository.InspectionRepository, bookingRepo repository.BookingRepository, carRepo repository.CarRepository) *InspectionService {
+	return &InspectionService{inspectionRepo: inspectionRepo, bookingRepo: bookingRepo, carRepo: carRepo}
+}
+
+// SubmitInspection records or replaces an inspection of the given kind on
+// behalf of the renter or the owner. The submitter signs it; any signature
+// from the other party is cleared, since they have not seen the new version.
+func (s *InspectionService) SubmitInspection(inspection *model.BookingInspection, userID uint) error {
+	statuses, ok := inspectionStatuses[inspection.Kind]
+	if !ok {
+		return ErrInvalidInspectionKind
+	}
+	if inspection.Odometer < 0 || inspection.FuelLevel < 0 || inspection.FuelLevel > 100 {
+		return ErrInvalidInspection
+	}
+
+	_, car, err := getBookingForParty(s.bookingRepo, s.carRepo, inspection.BookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		// Locking the booking serializes submissions for it and keeps its
+		// status from changing until the inspection is saved.
+		booking, err := lockInspectableBooking(repo, inspection.BookingID, statuses)
+		if err != nil {
+			return err
+		}
+
+		inspections := repo.Inspections()
+		existing, err := inspections.GetInspection(booking.ID, inspection.Kind)
+		switch {
+		case err == nil:
+			if existing.IsCountersigned() {
+				return ErrInspectionSigned
+			}
+			inspection.ID = existing.ID
+			inspection.CreatedAt = existing.CreatedAt
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			inspection.ID = 0
+		default:
+			return err
+		}
+
+		now := time.Now()
+		inspection.SubmittedBy = userID
+		inspection.RenterSignedAt = nil
+		inspection.OwnerSignedAt = nil
+		if partyOf(booking, car, userID) == partyOwner {
+			inspection.OwnerSignedAt = &now
+		} else {
+			inspection.RenterSignedAt = &now
+		}
+		return inspections.SaveInspection(inspection)
+	})
+}
+
+// SignInspection adds the signature of the party who did not submit the
+// inspection.
+func (s *InspectionService) SignInspection(bookingID uint, kind string, userID uint) (*model.BookingInspection, error) {
+	statuses, ok := inspectionStatuses[kind]
+	if !ok {
+		return nil, ErrInvalidInspectionKind
+	}
+	_, car, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var inspection *model.BookingInspection
+	err = s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		booking, err := lockInspectableBooking(repo, bookingID, statuses)
+		if err != nil {
+			return err
+		}
+
+		inspections := repo.Inspections()
+		inspection, err = inspections.GetInspection(booking.ID, kind)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInspectionNotFound
+			}
+			return err
+		}
+		if inspection.IsCountersigned() {
+			return ErrInspectionSigned
+		}
+
+		now := time.Now()
+		if partyOf(booking, car, userID) == partyOwner {
+			if inspection.OwnerSignedAt != nil {
+				return ErrAlreadySigned
+			}
+			inspection.OwnerSignedAt = &now
+		} else {
+			if inspection.RenterSignedAt != nil {
+				return ErrAlreadySigned
+			}
+			inspection.RenterSignedAt = &now
+		}
+		return inspections.SaveInspection(inspection)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inspection, nil
+}
+
+// lockInspectableBooking loads and locks a booking, checking that it is in
+// one of statuses.
+func lockInspectableBooking(repo repository.BookingRepository, bookingID uint, statuses []model.BookingStatus) (*model.Booking, error) {
+	booking, err := repo.GetBookingForUpdate(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	if !containsStatus(statuses, booking.Status) {
+		return nil, ErrInspectionNotAllowed
+	}
+	return booking, nil
+}
+
+// GetInspections returns a booking's inspections to its renter or owner.
+func (s *InspectionService) GetInspections(bookingID, userID uint) ([]model.BookingInspection, error) {
+	if _, _, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID); err != nil {
+		return nil, err
+	}
+	return s.inspectionRepo.GetInspectionsByBookingID(bookingID)
+}
+
+// DamageChange is an area whose condition differs between pickup and return.
+type DamageChange struct {
+	Area            string `json:"area"`
+	PickupCondition string `json:"pickup_condition"` // empty when the area was not checked at pickup
+	ReturnCondition string `json:"return_condition"`
+	Notes           string `json:"notes,omitempty"`
+}
+
+// InspectionComparison lists what changed between pickup and return.
+type InspectionComparison struct {
+	Pickup        *model.BookingInspection `json:"pickup"`
+	Return        *model.BookingInspection `json:"return"`
+	DistanceKm    int                      `json:"distance_km"`
+	FuelChange    int                      `json:"fuel_change"` // percentage points, negative when less fuel was returned
+	DamageChanges []DamageChange           `json:"damage_changes"`
+}
+
+// CompareInspections diffs the pickup and return inspections of a booking.
+// Both must have been submitted.
+func (s *InspectionService) CompareInspections(bookingID, userID uint) (*InspectionComparison, error) {
+	if _, _, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID); err != nil {
+		return nil, err
+	}
+
+	pickup, err := s.inspectionRepo.GetInspection(bookingID, model.InspectionPickup)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInspectionNotFound
+		}
+		return nil, err
+	}
+	ret, err := s.inspectionRepo.GetInspection(bookingID, model.InspectionReturn)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInspectionNotFound
+		}
+		return nil, err
+	}
+
+	comparison := &InspectionComparison{
+		Pickup:        pickup,
+		Return:        ret,
+		DistanceKm:    ret.Odometer - pickup.Odometer,
+		FuelChange:    ret.FuelLevel - pickup.FuelLevel,
+		DamageChanges: []DamageChange{},
+	}
+
+	before := make(map[string]string, len(pickup.DamageChecklist))
+	for _, item := range pickup.DamageChecklist {
+		before[item.Area] = item.Condition
+	}
+	for _, item := range ret.DamageChecklist {
+		if condition, ok := before[item.Area]; ok && condition == item.Condition {
+			continue
+		}
+		comparison.DamageChanges = append(comparison.DamageChanges, DamageChange{
+			Area:            item.Area,
+			PickupCondition: before[item.Area],
+			ReturnCondition: item.Condition,
+			Notes:           item.Notes,
+		})
+	}
+	return comparison, nil
+}
+
+func containsStatus(statuses []model.BookingStatus, status model.BookingStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// InstallmentOverdueWorker periodically flags unpaid installments whose due
+// date has passed, so every reader of the schedule sees them as overdue.
+type InstallmentOverdueWorker struct {
+	service  *InstallmentService
+	interval time.Duration
+}
+
+func NewInstallmentOverdueWorker(service *InstallmentService, interval time.Duration) *InstallmentOverdueWorker {
+	return &InstallmentOverdueWorker{service: service, interval: interval}
+}
+
+// Run checks for overdue installments straight away and then once per
+// interval until ctx is cancelled.
+func (w *InstallmentOverdueWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		overdue, err := w.service.MarkOverdueInstallments(time.Now())
+		if err != nil {
+			log.Printf("Installment overdue check failed: %v", err)
+		} else if overdue > 0 {
+			log.Printf("Marked %d installment(s) overdue", overdue)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInstallmentNotFound   = errors.New("installment not found")
+	ErrInstallmentSettled    = errors.New("installment is already paid or cancelled")
+	ErrInstallmentNotPayable = errors.New("installments can only be paid on confirmed bookings")
+)
+
+// payableStatuses are the booking statuses in which installments can be paid.
+var payableStatuses = []model.BookingStatus{model.BookingAccepted, model.BookingActive, model.BookingCompleted}
+
+// overdueStatuses are the booking statuses in which unpaid installments fall
+// overdue. A pending booking owes nothing until the owner accepts it.
+var overdueStatuses = []model.BookingStatus{model.BookingAccepted, model.BookingActive}
+
+// InstallmentService manages the monthly billing schedule of long-term
+// bookings.
+type InstallmentService struct {
+	installmentRepo repository.InstallmentRepository
+	bookingRepo     repository.BookingRepository
+	carRepo         repository.CarRepository
+}
+
+func NewInstallmentService(installmentRepo repository.InstallmentRepository, bookingRepo repository.BookingRepository, carRepo repository.CarRepository) *InstallmentService {
+	return &InstallmentService{installmentRepo: installmentRepo, bookingRepo: bookingRepo, carRepo: carRepo}
+}
+
+// GetInstallments returns the booking's billing schedule to its renter or
+// owner. On accepted and active bookings, unpaid installments past their due
+// date are marked overdue first.
+func (s *InstallmentService) GetInstallments(bookingID, userID uint) ([]model.BookingInstallment, error) {
+	booking, _, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if containsStatus(overdueStatuses, booking.Status) {
+		if err := s.installmentRepo.MarkOverdue(booking.ID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return s.installmentRepo.GetInstallmentsByBookingID(booking.ID)
+}
+
+// PayInstallment records the renter's payment of one installment.
+func (s *InstallmentService) PayInstallment(bookingID, installmentID, userID uint) (*model.BookingInstallment, error) {
+	var installment *model.BookingInstallment
+	err := s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		booking, err := repo.GetBookingForUpdate(bookingID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+		if booking.UserID != userID {
+			return ErrForbidden
+		}
+		if !containsStatus(payableStatuses, booking.Status) {
+			return ErrInstallmentNotPayable
+		}
+
+		installment, err = repo.Installments().GetInstallmentForUpdate(installmentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInstallmentNotFound
+			}
+			return err
+		}
+		if installment.BookingID != booking.ID {
+			return ErrInstallmentNotFound
+		}
+		if installment.Status != model.InstallmentPending && installment.Status != model.InstallmentOverdue {
+			return ErrInstallmentSettled
+		}
+
+		now := time.Now()
+		installment.Status = model.InstallmentPaid
+		installment.PaidAt = &now
+		return repo.Installments().UpdateInstallment(installment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return installment, nil
+}
+
+// MarkOverdueInstallments flags every unpaid installment due before now on
+// accepted and active bookings as overdue. It returns how many were flagged.
+func (s *InstallmentService) MarkOverdueInstallments(now time.Time) (int, error) {
+	n, err := s.installmentRepo.MarkAllOverdue(now, overdueStatuses)
+	return int(n), err
+}
+
+// paidInstallments sums the installments the renter has paid.
+func paidInstallments(installments []model.BookingInstallment) float64 {
+	paid := 0.0
+	for _, installment := range installments {
+		if installment.Status == model.InstallmentPaid {
+			paid += installment.Amount
+		}
+	}
+	return roundMoney(paid)
+}
+
+// installmentSchedule splits total into one installment per calendar month
+// of the rental in loc, each due when its month starts. Periods start on the
+// same day of the month as the rental, or on the month's last day when it is
+// shorter. Amounts are proportional to the length of each period; the last
+// one absorbs any rounding difference.
+func installmentSchedule(start, end time.Time, total float64, loc *time.Location) []model.BookingInstallment {
+	length := end.Sub(start)
+	local := start.In(loc)
+	var installments []model.BookingInstallment
+	remaining := total
+	for i := 0; ; i++ {
+		periodStart := addMonths(local, i).UTC()
+		periodEnd := addMonths(local, i+1).UTC()
+		last := !periodEnd.Before(end)
+		if last {
+			periodEnd = end
+		}
+
+		amount := roundMoney(total * float64(periodEnd.Sub(periodStart)) / float64(length))
+		if last {
+			amount = roundMoney(remaining)
+		}
+		remaining -= amount
+
+		installments = append(installments, model.BookingInstallment{
+			Sequence:    i + 1,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			DueDate:     periodStart,
+			Amount:      amount,
+			Status:      model.InstallmentPending,
+		})
+		if last {
+			return installments
+		}
+	}
+}
+
+// addMonths moves t forward by n calendar months, keeping its day of the
+// month where possible. Unlike time.AddDate, a day past the end of the
+// target month is clamped to its last day instead of rolling over, so
+// January 31 plus one month is February 28 or 29.
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
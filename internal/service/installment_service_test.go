@@ -0,0 +1,119 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"rentora-go/internal/model"
+)
+
+func TestInstallmentSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(loc *time.Location, year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 10, 0, 0, 0, loc).UTC()
+	}
+
+	tests := []struct {
+		name   string
+		start  time.Time
+		end    time.Time
+		total  float64
+		loc    *time.Location
+		starts []time.Time
+	}{
+		{
+			name:   "whole months",
+			start:  date(time.UTC, 2026, time.March, 1),
+			end:    date(time.UTC, 2026, time.June, 1),
+			total:  900,
+			loc:    time.UTC,
+			starts: []time.Time{date(time.UTC, 2026, time.March, 1), date(time.UTC, 2026, time.April, 1), date(time.UTC, 2026, time.May, 1)},
+		},
+		{
+			name:   "partial last month",
+			start:  date(time.UTC, 2026, time.March, 10),
+			end:    date(time.UTC, 2026, time.May, 1),
+			total:  500,
+			loc:    time.UTC,
+			starts: []time.Time{date(time.UTC, 2026, time.March, 10), date(time.UTC, 2026, time.April, 10)},
+		},
+		{
+			name:   "month end is clamped, not rolled over",
+			start:  date(time.UTC, 2026, time.January, 31),
+			end:    date(time.UTC, 2026, time.April, 15),
+			total:  1000,
+			loc:    time.UTC,
+			starts: []time.Time{date(time.UTC, 2026, time.January, 31), date(time.UTC, 2026, time.February, 28), date(time.UTC, 2026, time.March, 31)},
+		},
+		{
+			name:   "leap year February",
+			start:  date(time.UTC, 2028, time.January, 30),
+			end:    date(time.UTC, 2028, time.March, 15),
+			total:  700,
+			loc:    time.UTC,
+			starts: []time.Time{date(time.UTC, 2028, time.January, 30), date(time.UTC, 2028, time.February, 29)},
+		},
+		{
+			name:   "months follow the car's wall clock across DST",
+			start:  date(berlin, 2026, time.March, 15),
+			end:    date(berlin, 2026, time.May, 20),
+			total:  650,
+			loc:    berlin,
+			starts: []time.Time{date(berlin, 2026, time.March, 15), date(berlin, 2026, time.April, 15), date(berlin, 2026, time.May, 15)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installments := installmentSchedule(tt.start, tt.end, tt.total, tt.loc)
+			if len(installments) != len(tt.starts) {
+				t.Fatalf("got %d installments, want %d", len(installments), len(tt.starts))
+			}
+
+			sum := 0.0
+			for i, installment := range installments {
+				if installment.Sequence != i+1 {
+					t.Errorf("installment %d: sequence %d", i, installment.Sequence)
+				}
+				if !installment.PeriodStart.Equal(tt.starts[i]) {
+					t.Errorf("installment %d: starts %v, want %v", i, installment.PeriodStart, tt.starts[i])
+				}
+				if !installment.DueDate.Equal(installment.PeriodStart) {
+					t.Errorf("installment %d: due %v, want its period start", i, installment.DueDate)
+				}
+				if i > 0 && !installment.PeriodStart.Equal(installments[i-1].PeriodEnd) {
+					t.Errorf("installment %d: gap after the previous period", i)
+				}
+				if installment.Status != model.InstallmentPending {
+					t.Errorf("installment %d: status %q", i, installment.Status)
+				}
+				sum += installment.Amount
+			}
+			if last := installments[len(installments)-1]; !last.PeriodEnd.Equal(tt.end) {
+				t.Errorf("last period ends %v, want %v", last.PeriodEnd, tt.end)
+			}
+			if math.Abs(sum-tt.total) > 0.001 {
+				t.Errorf("installments add up to %.2f, want %.2f", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestPaidInstallments(t *testing.T) {
+	installments := []model.BookingInstallment{
+		{Amount: 310, Status: model.InstallmentPaid},
+		{Amount: 280, Status: model.InstallmentOverdue},
+		{Amount: 310.55, Status: model.InstallmentPaid},
+		{Amount: 100, Status: model.InstallmentCancelled},
+	}
+	if got := paidInstallments(installments); got != 620.55 {
+		t.Errorf("paidInstallments = %.2f, want 620.55", got)
+	}
+	if got := paidInstallments(nil); got != 0 {
+		t.Errorf("paidInstallments(nil) = %.2f, want 0", got)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"time"
+
+	"rentora-go/internal/model"
+)
+
+// Booking confirmation paths reported by CreateBooking.
+const (
+	ConfirmationInstant = "instant" // accepted straight away through Instant Book
+	ConfirmationRequest = "request" // waiting for the owner to accept
+)
+
+// instantBookIssues lists why renter may not instantly book a rental ending
+// at end. The license counts as expired from the start of its expiry date in
+// loc, the car's time zone. An empty result means the renter meets the car's
+// requirements.
+func instantBookIssues(renter *model.User, end time.Time, loc *time.Location) []string {
+	var issues []string
+	if !renter.IsActive {
+		issues = append(issues, "account is not active")
+	}
+	if !renter.IsVerified {
+		issues = append(issues, "account is not verified")
+	}
+	expiry := renter.DriversLicenseExpiration
+	if renter.DriversLicenseNumber == "" {
+		issues = append(issues, "no driver's license on file")
+	} else if !time.Date(expiry.Year(), expiry.Month(), expiry.Day(), 0, 0, 0, 0, loc).After(end) {
+		issues = append(issues, "driver's license expires before the rental ends")
+	}
+	return issues
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+)
+
+const (
+	maxMessageLength      = 4000
+	maxMessageAttachments = 10
+)
+
+var (
+	ErrEmptyMessage      = errors.New("message body is required")
+	ErrMessageTooLong    = errors.New("message body must be at most 4000 characters")
+	ErrInvalidAttachment = errors.New("attachments need a name, a non-negative size, a text/* content type and an http(s) URL, and at most 10 are allowed")
+)
+
+// MessageThread is a page of a booking's messages, newest first.
+type MessageThread struct {
+	BookingID uint                   `json:"booking_id"`
+	Messages  []model.BookingMessage `json:"messages"`
+	Total     int64                  `json:"total"`
+	Unread    int64                  `json:"unread"`
+}
+
+// MessageService runs the message thread between a booking's renter and the
+// car's owner. Nobody else can read or post in it.
+type MessageService struct {
+	messageRepo repository.MessageRepository
+	bookingRepo repository.BookingRepository
+	carRepo     repository.CarRepository
+}
+
+func NewMessageService(messageRepo repository.MessageRepository, bookingRepo repository.BookingRepository, carRepo repository.CarRepository) *MessageService {
+	return &MessageService{messageRepo: messageRepo, bookingRepo: bookingRepo, carRepo: carRepo}
+}
+
+// GetThread returns a page of the booking's thread and how many of its
+// messages userID has not read yet. Reading does not mark them read.
+func (s *MessageService) GetThread(bookingID, userID uint, limit, offset int) (*MessageThread, error) {
+	booking, _, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, total, err := s.messageRepo.GetMessages(booking.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.messageRepo.CountUnread(booking.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageThread{BookingID: booking.ID, Messages: messages, Total: total, Unread: unread}, nil
+}
+
+// PostMessage adds a message from userID to the booking's thread. The
+// sender's own message never counts as unread for them, and their read
+// marker is left alone so replies they have not seen stay unread.
+func (s *MessageService) PostMessage(message *model.BookingMessage, userID uint) error {
+	message.Body = strings.TrimSpace(message.Body)
+	if message.Body == "" {
+		return ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(message.Body) > maxMessageLength {
+		return ErrMessageTooLong
+	}
+	if len(message.Attachments) > maxMessageAttachments {
+		return ErrInvalidAttachment
+	}
+	for _, attachment := range message.Attachments {
+		if !validAttachment(attachment) {
+			return ErrInvalidAttachment
+		}
+	}
+
+	booking, _, err := getBookingForParty(s.bookingRepo, s.carRepo, message.BookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	message.ID = 0
+	message.BookingID = booking.ID
+	message.SenderID = userID
+	return s.messageRepo.CreateMessage(message)
+}
+
+// validAttachment checks an attachment's metadata. Threads only share text
+// files, and links must be plain web URLs so they cannot run script or reach
+// local files when opened.
+func validAttachment(attachment model.MessageAttachment) bool {
+	if strings.TrimSpace(attachment.Name) == "" || attachment.Size < 0 {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(attachment.ContentType)
+	if err != nil || !strings.HasPrefix(mediaType, "text/") {
+		return false
+	}
+	u, err := url.Parse(attachment.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return true
+}
+
+// MarkThreadRead marks every message currently in the booking's thread as
+// read by userID.
+func (s *MessageService) MarkThreadRead(bookingID, userID uint) error {
+	booking, _, err := getBookingForParty(s.bookingRepo, s.carRepo, bookingID, userID)
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.messageRepo.GetLatestMessageID(booking.ID)
+	if err != nil || latest == 0 {
+		return err
+	}
+	return s.messageRepo.MarkRead(booking.ID, userID, latest)
+}
+
+// GetUnreadCounts lists the user's threads that have unread messages.
+func (s *MessageService) GetUnreadCounts(userID uint) ([]repository.UnreadCount, error) {
+	return s.messageRepo.GetUnreadCounts(userID)
+}
@@ -0,0 +1,17 @@
+package service
+
+import "log"
+
+// Notifier delivers messages to users, for example by email or push.
+type Notifier interface {
+	Notify(userID uint, subject, message string) error
+}
+
+// LogNotifier writes notifications to the server log. It stands in until a
+// real delivery channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(userID uint, subject, message string) error {
+	log.Printf("Notify user %d: %s: %s", userID, subject, message)
+	return nil
+}
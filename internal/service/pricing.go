@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"rentora-go/internal/model"
+)
+
+var (
+	ErrCarNotPriced    = errors.New("car has neither a daily nor an hourly price")
+	ErrHourGranularity = errors.New("bookings on hourly priced cars must start and end on the hour")
+)
+
+// LongTermRentalDays is the rental length beyond which a booking gets the
+// car's monthly discount and is billed in monthly installments.
+const LongTermRentalDays = 30
+
+// PriceBreakdown is the itemized price of a booking as calculated from the
+// car's rates. Clients never supply amounts themselves.
+type PriceBreakdown struct {
+	Days         int                     `json:"days"`
+	Hours        int                     `json:"hours"` // started hours over the whole rental
+	PricePerDay  float64                 `json:"price_per_day"`
+	PricePerHour float64                 `json:"price_per_hour"`
+	LineItems    []model.BookingLineItem `json:"line_items"`
+	Subtotal     float64                 `json:"subtotal"`
+	Total        float64                 `json:"total"`
+}
+
+// Ways a single rental day can be charged.
+const (
+	chargeDaily  = iota // the daily rate
+	chargeHourly        // each started hour at the hourly rate
+	chargeCapped        // the hourly daily cap, when hours would cost more
+)
+
+// CalculatePrice prices a rental of car from start to end. The rental is
+// split into days counted from the start in the car's time zone, and each day
+// is charged whichever is cheapest of the daily rate and its started hours at
+// the hourly rate, the latter limited by the car's daily cap. As every full
+// day costs the same and only the last one can be partial, this is the
+// cheapest combination of the two rates.
+func CalculatePrice(car *model.Car, start, end time.Time) (*PriceBreakdown, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidBookingDates
+	}
+	if car.PricePerDay <= 0 && car.PricePerHour <= 0 {
+		return nil, ErrCarNotPriced
+	}
+
+	breakdown := &PriceBreakdown{PricePerDay: car.PricePerDay, PricePerHour: car.PricePerHour}
+	var counts [3]int // days charged each way
+	var hourlyHours int
+	for day := start.In(car.Zone()); day.Before(end); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		if next.After(end) {
+			next = end
+		}
+		hours := int(math.Ceil(next.Sub(day).Hours()))
+		breakdown.Days++
+		breakdown.Hours += hours
+
+		charge := cheapestDayCharge(car, hours)
+		counts[charge]++
+		if charge == chargeHourly {
+			hourlyHours += hours
+		}
+	}
+
+	if n := counts[chargeDaily]; n > 0 {
+		breakdown.LineItems = append(breakdown.LineItems, model.BookingLineItem{
+			Kind:        "rental",
+			Description: fmt.Sprintf("%d day(s) at %.2f per day", n, car.PricePerDay),
+			Quantity:    n,
+			UnitPrice:   car.PricePerDay,
+			Amount:      roundMoney(float64(n) * car.PricePerDay),
+		})
+	}
+	if n := counts[chargeCapped]; n > 0 {
+		breakdown.LineItems = append(breakdown.LineItems, model.BookingLineItem{
+			Kind:        "rental_capped",
+			Description: fmt.Sprintf("%d day(s) at the hourly daily cap of %.2f", n, car.DailyCap),
+			Quantity:    n,
+			UnitPrice:   car.DailyCap,
+			Amount:      roundMoney(float64(n) * car.DailyCap),
+		})
+	}
+	if hourlyHours > 0 {
+		breakdown.LineItems = append(breakdown.LineItems, model.BookingLineItem{
+			Kind:        "rental_hourly",
+			Description: fmt.Sprintf("%d hour(s) at %.2f per hour", hourlyHours, car.PricePerHour),
+			Quantity:    hourlyHours,
+			UnitPrice:   car.PricePerHour,
+			Amount:      roundMoney(float64(hourlyHours) * car.PricePerHour),
+		})
+	}
+
+	if breakdown.Days > LongTermRentalDays && car.MonthlyDiscountPercent > 0 {
+		var rental float64
+		for _, item := range breakdown.LineItems {
+			rental += item.Amount
+		}
+		discount := roundMoney(rental * car.MonthlyDiscountPercent / 100)
+		breakdown.LineItems = append(breakdown.LineItems, model.BookingLineItem{
+			Kind:        "monthly_discount",
+			Description: fmt.Sprintf("%g%% monthly discount", car.MonthlyDiscountPercent),
+			Quantity:    1,
+			UnitPrice:   -discount,
+			Amount:      -discount,
+		})
+	}
+	for _, item := range breakdown.LineItems {
+		breakdown.Subtotal += item.Amount
+	}
+	breakdown.Subtotal = roundMoney(breakdown.Subtotal)
+	breakdown.Total = breakdown.Subtotal
+	return breakdown, nil
+}
+
+// addItems adds further line items, such as extras, to the breakdown's
+// totals.
+func (b *PriceBreakdown) addItems(items []model.BookingLineItem) {
+	for _, item := range items {
+		b.LineItems = append(b.LineItems, item)
+		b.Subtotal += item.Amount
+	}
+	b.Subtotal = roundMoney(b.Subtotal)
+	b.Total = b.Subtotal
+}
+
+// cheapestDayCharge picks how to charge a rental day of the given started
+// hours. Rates the car does not offer are left out; ties go to the daily
+// rate.
+func cheapestDayCharge(car *model.Car, hours int) int {
+	if car.PricePerHour <= 0 {
+		return chargeDaily
+	}
+
+	charge, cost := chargeHourly, float64(hours)*car.PricePerHour
+	if car.DailyCap > 0 && car.DailyCap < cost {
+		charge, cost = chargeCapped, car.DailyCap
+	}
+	if car.PricePerDay > 0 && car.PricePerDay <= cost {
+		charge = chargeDaily
+	}
+	return charge
+}
+
+// checkHourGranularity requires bookings on hourly priced cars to start and
+// end on a full hour in the car's time zone.
+func checkHourGranularity(car *model.Car, start, end time.Time) error {
+	if car.PricePerHour <= 0 {
+		return nil
+	}
+	for _, t := range []time.Time{start, end} {
+		local := t.In(car.Zone())
+		if local.Minute() != 0 || local.Second() != 0 || local.Nanosecond() != 0 {
+			return ErrHourGranularity
+		}
+	}
+	return nil
+}
+
+// rentalDays counts started days from start to end, so any part of a day is
+// charged as a full day. Days run from one wall-clock time to the same time
+// the next day in loc, so a day that gains or loses an hour to daylight
+// saving still counts once.
+func rentalDays(start, end time.Time, loc *time.Location) int {
+	days := 0
+	for day := start.In(loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
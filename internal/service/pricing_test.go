@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rentora-go/internal/model"
+)
+
+func TestCalculatePrice(t *testing.T) {
+	start := time.Date(2026, time.June, 1, 10, 0, 0, 0, time.UTC)
+	berlin := func(month time.Month, day, hour int) time.Time {
+		loc, err := time.LoadLocation("Europe/Berlin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2026, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		car   model.Car
+		start time.Time
+		end   time.Time
+		days  int
+		hours int
+		items map[string]float64 // line item amount by kind
+		total float64
+	}{
+		{
+			name:  "whole days at the daily rate",
+			car:   model.Car{PricePerDay: 50},
+			start: start,
+			end:   start.AddDate(0, 0, 3),
+			days:  3,
+			hours: 72,
+			items: map[string]float64{"rental": 150},
+			total: 150,
+		},
+		{
+			name:  "a started day counts in full without an hourly rate",
+			car:   model.Car{PricePerDay: 50},
+			start: start,
+			end:   start.AddDate(0, 0, 2).Add(time.Hour),
+			days:  3,
+			hours: 49,
+			items: map[string]float64{"rental": 150},
+			total: 150,
+		},
+		{
+			name:  "hourly only",
+			car:   model.Car{PricePerHour: 10},
+			start: start,
+			end:   start.Add(5 * time.Hour),
+			days:  1,
+			hours: 5,
+			items: map[string]float64{"rental_hourly": 50},
+			total: 50,
+		},
+		{
+			name:  "hourly limited by the daily cap",
+			car:   model.Car{PricePerHour: 10, DailyCap: 80},
+			start: start,
+			end:   start.Add(30 * time.Hour),
+			days:  2,
+			hours: 30,
+			items: map[string]float64{"rental_capped": 80, "rental_hourly": 60},
+			total: 140,
+		},
+		{
+			name:  "full days daily, short last day hourly",
+			car:   model.Car{PricePerDay: 60, PricePerHour: 15},
+			start: start,
+			end:   start.AddDate(0, 0, 2).Add(3 * time.Hour),
+			days:  3,
+			hours: 51,
+			items: map[string]float64{"rental": 120, "rental_hourly": 45},
+			total: 165,
+		},
+		{
+			name:  "ties go to the daily rate",
+			car:   model.Car{PricePerDay: 60, PricePerHour: 15},
+			start: start,
+			end:   start.Add(4 * time.Hour),
+			days:  1,
+			hours: 4,
+			items: map[string]float64{"rental": 60},
+			total: 60,
+		},
+		{
+			name:  "cap cheaper than the daily rate",
+			car:   model.Car{PricePerDay: 100, PricePerHour: 10, DailyCap: 70},
+			start: start,
+			end:   start.AddDate(0, 0, 1),
+			days:  1,
+			hours: 24,
+			items: map[string]float64{"rental_capped": 70},
+			total: 70,
+		},
+		{
+			name:  "spring forward day has 23 hours",
+			car:   model.Car{PricePerHour: 10, TimeZone: "Europe/Berlin"},
+			start: berlin(time.March, 28, 10),
+			end:   berlin(time.March, 30, 10),
+			days:  2,
+			hours: 47,
+			items: map[string]float64{"rental_hourly": 470},
+			total: 470,
+		},
+		{
+			name:  "fall back day has 25 hours but is still one day",
+			car:   model.Car{PricePerDay: 40, PricePerHour: 10, TimeZone: "Europe/Berlin"},
+			start: berlin(time.October, 24, 10),
+			end:   berlin(time.October, 26, 10),
+			days:  2,
+			hours: 49,
+			items: map[string]float64{"rental": 80},
+			total: 80,
+		},
+		{
+			name:  "monthly discount beyond 30 days",
+			car:   model.Car{PricePerDay: 50, MonthlyDiscountPercent: 10},
+			start: start,
+			end:   start.AddDate(0, 0, 31),
+			days:  31,
+			hours: 744,
+			items: map[string]float64{"rental": 1550, "monthly_discount": -155},
+			total: 1395,
+		},
+		{
+			name:  "no monthly discount at 30 days",
+			car:   model.Car{PricePerDay: 50, MonthlyDiscountPercent: 10},
+			start: start,
+			end:   start.AddDate(0, 0, 30),
+			days:  30,
+			hours: 720,
+			items: map[string]float64{"rental": 1500},
+			total: 1500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := CalculatePrice(&tt.car, tt.start.UTC(), tt.end.UTC())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if price.Days != tt.days || price.Hours != tt.hours {
+				t.Errorf("got %d day(s) and %d hour(s), want %d and %d", price.Days, price.Hours, tt.days, tt.hours)
+			}
+			if len(price.LineItems) != len(tt.items) {
+				t.Fatalf("got line items %+v, want %v", price.LineItems, tt.items)
+			}
+			for _, item := range price.LineItems {
+				if want, ok := tt.items[item.Kind]; !ok || item.Amount != want {
+					t.Errorf("%s line item %.2f, want %v", item.Kind, item.Amount, tt.items)
+				}
+			}
+			if price.Subtotal != tt.total || price.Total != tt.total {
+				t.Errorf("subtotal %.2f, total %.2f, want %.2f", price.Subtotal, price.Total, tt.total)
+			}
+		})
+	}
+}
+
+func TestCalculatePriceErrors(t *testing.T) {
+	start := time.Date(2026, time.June, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		car  model.Car
+		end  time.Time
+		want error
+	}{
+		{"end before start", model.Car{PricePerDay: 50}, start.Add(-time.Hour), ErrInvalidBookingDates},
+		{"empty range", model.Car{PricePerDay: 50}, start, ErrInvalidBookingDates},
+		{"no rates", model.Car{}, start.Add(time.Hour), ErrCarNotPriced},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CalculatePrice(&tt.car, start, tt.end); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheapestDayCharge(t *testing.T) {
+	tests := []struct {
+		name  string
+		car   model.Car
+		hours int
+		want  int
+	}{
+		{"daily only", model.Car{PricePerDay: 50}, 24, chargeDaily},
+		{"hourly only", model.Car{PricePerHour: 5}, 24, chargeHourly},
+		{"hourly cheaper", model.Car{PricePerDay: 50, PricePerHour: 5}, 3, chargeHourly},
+		{"daily cheaper", model.Car{PricePerDay: 50, PricePerHour: 5}, 11, chargeDaily},
+		{"tie goes daily", model.Car{PricePerDay: 50, PricePerHour: 5}, 10, chargeDaily},
+		{"cap cheapest", model.Car{PricePerDay: 50, PricePerHour: 5, DailyCap: 40}, 12, chargeCapped},
+		{"cap above hourly cost is ignored", model.Car{PricePerHour: 5, DailyCap: 40}, 6, chargeHourly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cheapestDayCharge(&tt.car, tt.hours); got != tt.want {
+				t.Errorf("cheapestDayCharge(%d hours) = %d, want %d", tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHourGranularity(t *testing.T) {
+	onHour := time.Date(2026, time.June, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		car   model.Car
+		start time.Time
+		want  error
+	}{
+		{"daily cars are not restricted", model.Car{PricePerDay: 50}, onHour.Add(15 * time.Minute), nil},
+		{"hourly on the hour", model.Car{PricePerHour: 10}, onHour, nil},
+		{"hourly off the hour", model.Car{PricePerHour: 10}, onHour.Add(15 * time.Minute), ErrHourGranularity},
+		{"hour is read in the car's zone", model.Car{PricePerHour: 10, TimeZone: "Asia/Kolkata"}, onHour, ErrHourGranularity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkHourGranularity(&tt.car, tt.start, tt.start.Add(3*time.Hour)); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
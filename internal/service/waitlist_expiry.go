@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// WaitlistHoldWorker periodically closes waitlist holds that ran out and
+// passes their dates on to the next renter in line.
+type WaitlistHoldWorker struct {
+	service  *WaitlistService
+	interval time.Duration
+}
+
+func NewWaitlistHoldWorker(service *WaitlistService, interval time.Duration) *WaitlistHoldWorker {
+	return &WaitlistHoldWorker{service: service, interval: interval}
+}
+
+// Run checks for expired holds straight away and then once per interval
+// until ctx is cancelled.
+func (w *WaitlistHoldWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := w.service.ExpireHolds(time.Now())
+		if err != nil {
+			log.Printf("Waitlist hold expiry failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d waitlist hold(s)", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
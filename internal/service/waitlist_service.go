@@ -0,0 +1,300 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"rentora-go/internal/model"
+	"rentora-go/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+	ErrDatesAvailable        = errors.New("the requested dates are available and can be booked directly")
+	ErrAlreadyWaitlisted     = errors.New("already on the waitlist for these dates")
+	ErrWaitlistEntryClosed   = errors.New("waitlist entry is no longer active")
+)
+
+// DatesHeldError is returned when a requested date range overlaps dates held
+// for a renter from the waitlist.
+type DatesHeldError struct {
+	EntryID   uint
+	HeldUntil time.Time
+}
+
+func (e *DatesHeldError) Error() string {
+	return fmt.Sprintf("dates are held for another renter until %s", e.HeldUntil.Format(time.RFC3339))
+}
+
+// WaitlistService lets renters queue for dates on a fully booked car. When a
+// conflicting booking is declined, cancelled or expires, the first waiting
+// renter whose dates are now free is offered a hold on them for holdDuration.
+type WaitlistService struct {
+	waitlistRepo repository.WaitlistRepository
+	bookingRepo  repository.BookingRepository
+	carRepo      repository.CarRepository
+	notifier     Notifier
+	holdDuration time.Duration
+}
+
+func NewWaitlistService(waitlistRepo repository.WaitlistRepository, bookingRepo repository.BookingRepository, carRepo repository.CarRepository, notifier Notifier, holdDuration time.Duration) *WaitlistService {
+	return &WaitlistService{
+		waitlistRepo: waitlistRepo,
+		bookingRepo:  bookingRepo,
+		carRepo:      carRepo,
+		notifier:     notifier,
+		holdDuration: holdDuration,
+	}
+}
+
+// JoinWaitlist adds the renter in entry to the car's waitlist. Only dates
+// that are currently taken, by a booking, a hold or a block, can be waited
+// for.
+func (s *WaitlistService) JoinWaitlist(entry *model.WaitlistEntry) error {
+	entry.StartDate = entry.StartDate.UTC()
+	entry.EndDate = entry.EndDate.UTC()
+	now := time.Now()
+	if !entry.EndDate.After(entry.StartDate) || !entry.StartDate.After(now) {
+		return ErrInvalidBookingDates
+	}
+
+	car, err := s.carRepo.GetCarByID(entry.CarID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCarNotFound
+		}
+		return err
+	}
+	if car.OwnerID == entry.UserID {
+		return ErrOwnCarBooking
+	}
+
+	conflicts, err := s.bookingRepo.FindOverlappingBookings(car.ID, entry.StartDate, entry.EndDate, blockingStatuses)
+	if err != nil {
+		return err
+	}
+	blocks, err := s.bookingRepo.FindOverlappingBlocks(car.ID, entry.StartDate, entry.EndDate)
+	if err != nil {
+		return err
+	}
+	holds, err := s.waitlistRepo.FindActiveHolds(car.ID, entry.StartDate, entry.EndDate, now)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 && len(blocks) == 0 && len(holdsForOthers(holds, entry.UserID)) == 0 {
+		return ErrDatesAvailable
+	}
+
+	waiting, err := s.waitlistRepo.FindWaitingEntries(car.ID, entry.StartDate, entry.EndDate)
+	if err != nil {
+		return err
+	}
+	for _, other := range waiting {
+		if other.UserID == entry.UserID {
+			return ErrAlreadyWaitlisted
+		}
+	}
+
+	entry.Status = model.WaitlistWaiting
+	entry.HoldExpiresAt = nil
+	entry.BookingID = nil
+	return s.waitlistRepo.CreateEntry(entry)
+}
+
+// CarZone returns the time zone in which wall-clock waitlist dates for the
+// car are read.
+func (s *WaitlistService) CarZone(carID uint) (*time.Location, error) {
+	return carZone(s.carRepo, carID)
+}
+
+// GetMyEntries lists the renter's waitlist entries, newest first.
+func (s *WaitlistService) GetMyEntries(userID uint) ([]model.WaitlistEntry, error) {
+	return s.waitlistRepo.GetEntriesByUserID(userID)
+}
+
+// LeaveWaitlist removes the renter from the waitlist. Giving up a hold
+// passes the dates on to the next renter in line.
+func (s *WaitlistService) LeaveWaitlist(entryID, userID uint) error {
+	var offer *model.WaitlistEntry
+	err := s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+		entry, err := repo.Waitlist().GetEntryForUpdate(entryID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWaitlistEntryNotFound
+			}
+			return err
+		}
+		if entry.UserID != userID {
+			return ErrForbidden
+		}
+		if entry.Status != model.WaitlistWaiting && entry.Status != model.WaitlistOffered {
+			return ErrWaitlistEntryClosed
+		}
+		if _, err := repo.GetCarForUpdate(entry.CarID); err != nil {
+			return err
+		}
+
+		wasOffered := entry.Status == model.WaitlistOffered
+		entry.Status = model.WaitlistCancelled
+		if err := repo.Waitlist().UpdateEntry(entry); err != nil {
+			return err
+		}
+		if wasOffered {
+			offer, err = s.offerFreedDates(repo, entry.CarID, entry.StartDate, entry.EndDate, time.Now())
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyOffer(offer)
+	return nil
+}
+
+// ExpireHolds closes holds that ran out before now and offers their dates to
+// the next renter in line. It returns how many holds expired. A hold that
+// fails to expire is logged and skipped so it does not hold up the rest of
+// the batch.
+func (s *WaitlistService) ExpireHolds(now time.Time) (int, error) {
+	entries, err := s.waitlistRepo.GetExpiredHolds(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, stale := range entries {
+		var offer *model.WaitlistEntry
+		closed := false
+		err := s.bookingRepo.Transaction(func(repo repository.BookingRepository) error {
+			if _, err := repo.GetCarForUpdate(stale.CarID); err != nil {
+				return err
+			}
+			// The renter may have booked or left since the holds were listed.
+			entry, err := repo.Waitlist().GetEntryForUpdate(stale.ID)
+			if err != nil {
+				return err
+			}
+			if entry.Status != model.WaitlistOffered || entry.HoldExpiresAt.After(now) {
+				return nil
+			}
+
+			entry.Status = model.WaitlistExpired
+			if err := repo.Waitlist().UpdateEntry(entry); err != nil {
+				return err
+			}
+			closed = true
+			offer, err = s.offerFreedDates(repo, entry.CarID, entry.StartDate, entry.EndDate, now)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to expire waitlist hold %d: %v", stale.ID, err)
+			continue
+		}
+		if closed {
+			expired++
+		}
+		s.notifyOffer(offer)
+	}
+	return expired, nil
+}
+
+// offerFreedDates offers a hold to the first waiting renter whose dates
+// intersect [start, end) and are now entirely free. It must run inside a
+// transaction holding the car's lock. It returns the offered entry, if any,
+// so the caller can notify the renter once the transaction commits.
+func (s *WaitlistService) offerFreedDates(repo repository.BookingRepository, carID uint, start, end, now time.Time) (*model.WaitlistEntry, error) {
+	entries, err := repo.Waitlist().FindWaitingEntries(carID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.StartDate.After(now) {
+			continue
+		}
+		free, err := s.datesFree(repo, entry, now)
+		if err != nil {
+			return nil, err
+		}
+		if !free {
+			continue
+		}
+
+		heldUntil := now.Add(s.holdDuration)
+		entry.Status = model.WaitlistOffered
+		entry.HoldExpiresAt = &heldUntil
+		if err := repo.Waitlist().UpdateEntry(entry); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+	return nil, nil
+}
+
+// datesFree reports whether nothing else holds the entry's dates.
+func (s *WaitlistService) datesFree(repo repository.BookingRepository, entry *model.WaitlistEntry, now time.Time) (bool, error) {
+	conflicts, err := repo.FindOverlappingBookings(entry.CarID, entry.StartDate, entry.EndDate, blockingStatuses)
+	if err != nil || len(conflicts) > 0 {
+		return false, err
+	}
+	blocks, err := repo.FindOverlappingBlocks(entry.CarID, entry.StartDate, entry.EndDate)
+	if err != nil || len(blocks) > 0 {
+		return false, err
+	}
+	holds, err := repo.Waitlist().FindActiveHolds(entry.CarID, entry.StartDate, entry.EndDate, now)
+	if err != nil {
+		return false, err
+	}
+	return len(holds) == 0, nil
+}
+
+// checkHolds rejects a booking that overlaps dates held for someone else and
+// returns the renter's own holds on those dates.
+func (s *WaitlistService) checkHolds(repo repository.BookingRepository, booking *model.Booking, now time.Time) ([]model.WaitlistEntry, error) {
+	holds, err := repo.Waitlist().FindActiveHolds(booking.CarID, booking.StartDate, booking.EndDate, now)
+	if err != nil {
+		return nil, err
+	}
+	if others := holdsForOthers(holds, booking.UserID); len(others) > 0 {
+		return nil, &DatesHeldError{EntryID: others[0].ID, HeldUntil: *others[0].HoldExpiresAt}
+	}
+	return holds, nil
+}
+
+// claimHolds marks the renter's holds as used by booking.
+func (s *WaitlistService) claimHolds(repo repository.BookingRepository, holds []model.WaitlistEntry, booking *model.Booking) error {
+	for i := range holds {
+		holds[i].Status = model.WaitlistBooked
+		holds[i].BookingID = &booking.ID
+		if err := repo.Waitlist().UpdateEntry(&holds[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WaitlistService) notifyOffer(entry *model.WaitlistEntry) {
+	if entry == nil {
+		return
+	}
+	message := fmt.Sprintf("The car you are waiting for is free from %s to %s. It is held for you until %s.",
+		entry.StartDate.Format(time.RFC3339), entry.EndDate.Format(time.RFC3339), entry.HoldExpiresAt.Format(time.RFC3339))
+	if err := s.notifier.Notify(entry.UserID, "Your waitlisted dates are available", message); err != nil {
+		log.Printf("Failed to notify user %d about waitlist entry %d: %v", entry.UserID, entry.ID, err)
+	}
+}
+
+func holdsForOthers(holds []model.WaitlistEntry, userID uint) []model.WaitlistEntry {
+	var others []model.WaitlistEntry
+	for _, hold := range holds {
+		if hold.UserID != userID {
+			others = append(others, hold)
+		}
+	}
+	return others
+}